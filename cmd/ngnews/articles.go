@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+)
+
+var (
+	fullScrape bool
+	noEnqueue  bool
+)
+
+func articlesFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&fullScrape, "full", false, "re-scrape every sitemap URL instead of only new or changed ones, and retry failed jobs")
+	fs.BoolVar(&noEnqueue, "no-enqueue", false, "only work through jobs already in go_scrape_jobs")
+}
+
+// runArticles queues the site's new and updated sitemap URLs in
+// go_scrape_jobs and works through the queue with --workers workers. Jobs
+// are claimed from the database, so several processes can run this command
+// against the same site, and a run that dies part-way resumes where it
+// stopped. --since bounds the sitemap lastmod of queued URLs and --limit
+// caps the number of jobs this process handles.
+func runArticles(a *app) error {
+	websiteConfig := a.site
+	articleScraper := scraper.NewArticleScraper(a.db, websiteConfig)
+	queue := scraper.NewJobQueue(a.db, websiteConfig)
+
+	recovered, err := queue.RecoverStale()
+	if err != nil {
+		return err
+	}
+	if recovered > 0 {
+		log.Printf("Recovered %d stale jobs", recovered)
+	}
+
+	if !noEnqueue {
+		queued, err := queue.Enqueue(scraper.EnqueueOptions{Since: a.since, Full: fullScrape})
+		if err != nil {
+			return err
+		}
+		log.Printf("Queued %d articles", queued)
+	}
+
+	counts, err := queue.Counts()
+	if err != nil {
+		return err
+	}
+	log.Printf("Found %d articles to process", counts[scraper.JobPending])
+
+	var processed, claimed int64
+	var wg sync.WaitGroup
+
+	// Start workers
+	for i := 0; i < websiteConfig.MaxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if a.opts.limit > 0 && atomic.AddInt64(&claimed, 1) > int64(a.opts.limit) {
+					return
+				}
+
+				job, err := queue.Claim()
+				if err != nil {
+					log.Printf("Error claiming job: %v", err)
+					return
+				}
+				if job == nil {
+					return
+				}
+
+				if err := scrapeJob(articleScraper, job); fetch.IsDisallowed(err) {
+					if err := queue.Skip(job, "disallowed by robots.txt"); err != nil {
+						log.Printf("Error recording skip: %v", err)
+					}
+				} else if err != nil {
+					log.Printf("Error processing article %s: %v", job.URL, err)
+					if err := queue.Fail(job, err); err != nil {
+						log.Printf("Error recording failure: %v", err)
+					}
+				} else if err := queue.Complete(job); err != nil {
+					log.Printf("Error completing job: %v", err)
+				}
+
+				if n := atomic.AddInt64(&processed, 1); n%100 == 0 {
+					log.Printf("Progress: %d articles processed", n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts, err = queue.Counts()
+	if err != nil {
+		return err
+	}
+	log.Printf("Article scraping completed: %d processed, %d pending, %d failed",
+		processed, counts[scraper.JobPending], counts[scraper.JobFailed])
+	return nil
+}
+
+// scrapeJob fetches, extracts and stores one article. Unless --full is
+// given the article is requested conditionally, and a 304 response only
+// records the visit. Thumbnails of the article's images are stored when the
+// site sets thumbnail_dir.
+func scrapeJob(articleScraper *scraper.ArticleScraper, job *scraper.Job) error {
+	scrape := articleScraper.ScrapeArticleIfModified
+	if fullScrape {
+		scrape = articleScraper.ScrapeArticle
+	}
+	article, err := scrape(job.URL)
+	if errors.Is(err, fetch.ErrNotModified) {
+		return articleScraper.MarkUnchanged(job.URL)
+	}
+	if err != nil {
+		return err
+	}
+	if err := articleScraper.SaveArticle(article); err != nil {
+		return err
+	}
+	return articleScraper.SaveThumbnails(article)
+}
@@ -0,0 +1,10 @@
+package main
+
+import "github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+
+// runCategories imports the site's category sitemap. Categories are a
+// prerequisite for linking articles, so run-all performs this step first.
+func runCategories(a *app) error {
+	categoryScraper := scraper.NewCategoryScraper(a.db, a.site)
+	return categoryScraper.ScrapeCategories()
+}
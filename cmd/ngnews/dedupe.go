@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+)
+
+var dryRun bool
+
+func dedupeFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&dryRun, "dry-run", false, "report what would be merged without changing anything")
+}
+
+// runDedupe merges articles that were stored under non-canonical URLs
+// (tracking parameters, http, www, AMP or a missing trailing slash) into
+// their canonical row, and records the canonical URL of sitemap rows that
+// lack one. Sitemap URLs themselves are kept as listed.
+func runDedupe(a *app) error {
+	stats, err := scraper.NewDeduper(a.db, a.site).Run(dryRun)
+	if err != nil {
+		return err
+	}
+
+	prefix := "Dedupe completed"
+	if dryRun {
+		prefix = "Dedupe dry run"
+	}
+	log.Printf("%s: %d duplicate articles merged, %d renamed, %d sitemap URLs keyed",
+		prefix, stats.ArticlesMerged, stats.ArticlesRenamed, stats.SitemapKeyed)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+)
+
+// runHashCheck scrapes the same article twice and reports whether the
+// content hash is stable, which the change detection in SaveArticle relies on.
+func runHashCheck(a *app) error {
+	if len(a.args) != 1 {
+		return fmt.Errorf("hash-check expects exactly one article URL")
+	}
+	url := a.args[0]
+
+	articleScraper := scraper.NewArticleScraper(a.db, a.site)
+
+	// First scrape
+	article1, err := articleScraper.ScrapeArticle(url)
+	if err != nil {
+		return err
+	}
+	hash1 := scraper.CalculateContentHash(article1.Markdown)
+
+	// Second scrape
+	article2, err := articleScraper.ScrapeArticle(url)
+	if err != nil {
+		return err
+	}
+	hash2 := scraper.CalculateContentHash(article2.Markdown)
+
+	fmt.Printf("Hash1: %s\n", hash1)
+	fmt.Printf("Hash2: %s\n", hash2)
+	fmt.Printf("Hashes match: %v\n", hash1 == hash2)
+	return nil
+}
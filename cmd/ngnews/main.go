@@ -0,0 +1,197 @@
+// The ngnews command is the single entry point for the Nigerian news scraper.
+// Each stage of the pipeline is a subcommand sharing one configuration loader
+// and one database bootstrap:
+//
+//	ngnews sitemaps   --site 1           ingest article URLs from post sitemaps
+//	ngnews categories --site blueprint   import the category sitemap
+//	ngnews tags       --site blueprint   import the tag sitemaps
+//	ngnews articles   --workers 5        scrape and store queued articles
+//	ngnews hash-check URL                scrape a URL twice and compare hashes
+//	ngnews reparse    [URL...]           re-extract articles from the raw archive
+//	ngnews dedupe     --dry-run          merge articles stored under non-canonical URLs
+//	ngnews run-all                       categories, tags, sitemaps, then articles
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/database"
+)
+
+// options holds the flags shared by every subcommand.
+type options struct {
+	configPath string
+	site       string
+	workers    int
+	limit      int
+	since      string
+}
+
+// app carries the resolved configuration and database handle for a run.
+type app struct {
+	opts     options
+	args     []string
+	settings *config.Settings
+	site     config.WebsiteConfig
+	db       *sql.DB
+	since    time.Time // Zero when --since was not given
+}
+
+// command describes one subcommand. flags, when set, registers
+// command-specific flags before parsing.
+type command struct {
+	name    string
+	summary string
+	flags   func(fs *flag.FlagSet)
+	run     func(a *app) error
+}
+
+var commands = []command{
+	{name: "sitemaps", summary: "ingest article URLs from the site's post and news sitemaps", flags: sitemapsFlags, run: runSitemaps},
+	{name: "categories", summary: "import categories from the category sitemap", run: runCategories},
+	{name: "tags", summary: "import tags from the tag sitemaps", run: runTags},
+	{name: "articles", summary: "scrape new and updated articles listed in go_sitemaps", flags: articlesFlags, run: runArticles},
+	{name: "hash-check", summary: "scrape a URL twice and check the content hash is stable", run: runHashCheck},
+	{name: "reparse", summary: "re-extract archived articles without network access", run: runReparse},
+	{name: "dedupe", summary: "merge articles stored under non-canonical URLs and key sitemap rows", flags: dedupeFlags, run: runDedupe},
+	{name: "run-all", summary: "run categories, tags, sitemaps and articles in order", flags: articlesFlags, run: runAll},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := lookupCommand(os.Args[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	var opts options
+	fs.StringVar(&opts.configPath, "config", "", "path to a YAML or JSON config file (default $NG_NEWS_CONFIG)")
+	fs.StringVar(&opts.site, "site", "", "website ID or name (optional when only one is configured)")
+	fs.IntVar(&opts.workers, "workers", 0, "number of concurrent workers (default from site config)")
+	fs.IntVar(&opts.limit, "limit", 0, "maximum number of items to process, 0 for no limit")
+	fs.StringVar(&opts.since, "since", "", "only process items modified since a date (2006-01-02), RFC3339 time or duration (48h)")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Parse(os.Args[2:])
+
+	a, err := newApp(opts, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.db.Close()
+
+	if err := cmd.run(a); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage() {
+	var b strings.Builder
+	b.WriteString("usage: ngnews <command> [flags] [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	b.WriteString("\nRun 'ngnews <command> -h' for the flags of a command.\n")
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// newApp loads configuration, resolves the selected website, applies flag
+// overrides and opens the database.
+func newApp(opts options, args []string) (*app, error) {
+	settings, err := config.Load(opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	site, err := settings.Website(opts.site)
+	if err != nil {
+		return nil, err
+	}
+	if opts.workers > 0 {
+		site.MaxWorkers = opts.workers
+	}
+
+	since, err := parseSince(opts.since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.Open(settings.DB)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Printf("Using website %d (%s)", site.ID, site.Name)
+	return &app{
+		opts:     opts,
+		args:     args,
+		settings: settings,
+		site:     site,
+		db:       db,
+		since:    since,
+	}, nil
+}
+
+// parseSince accepts a date, an RFC3339 timestamp or a Go duration counted
+// back from now. An empty value yields the zero time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q", value)
+}
+
+func runAll(a *app) error {
+	steps := []struct {
+		name string
+		run  func(*app) error
+	}{
+		{"categories", runCategories},
+		{"tags", runTags},
+		{"sitemaps", runSitemaps},
+		{"articles", runArticles},
+	}
+	for _, step := range steps {
+		log.Printf("=== %s ===", step.name)
+		if err := step.run(a); err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+	return nil
+}
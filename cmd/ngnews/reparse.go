@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+)
+
+// runReparse re-extracts articles from the raw page archive and saves them,
+// without touching the network. With URL arguments only those pages are
+// reparsed; otherwise every archived page of the site is, newest first,
+// filtered by --since (fetch time) and capped by --limit.
+func runReparse(a *app) error {
+	articleScraper := scraper.NewArticleScraper(a.db, a.site)
+
+	urls := a.args
+	if len(urls) == 0 {
+		var err error
+		if urls, err = articleScraper.ArchivedURLs(a.since, a.opts.limit); err != nil {
+			return err
+		}
+	}
+	log.Printf("Reparsing %d archived articles", len(urls))
+
+	var reparsed, failed int
+	for _, url := range urls {
+		article, err := articleScraper.ReparseArticle(url)
+		if err == scraper.ErrNoArchive {
+			return err
+		}
+		if err == nil {
+			err = articleScraper.SaveArticle(article)
+		}
+		if err != nil {
+			failed++
+			log.Printf("Error reparsing %s: %v", url, err)
+			continue
+		}
+		reparsed++
+	}
+
+	log.Printf("Reparse completed: %d reparsed, %d failed", reparsed, failed)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+)
+
+var noDiscover bool
+
+func sitemapsFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&noDiscover, "no-discover", false, "skip robots.txt discovery and use the configured sitemap_format range")
+	fs.BoolVar(&fullScrape, "full", false, "re-download every sitemap even if the server reports it unchanged")
+}
+
+// runSitemaps ingests article URLs from the site's post and news sitemaps
+// into go_sitemaps. Sitemaps are discovered from robots.txt and sitemap
+// indexes unless --no-discover is given. Sitemaps unchanged since the last
+// run are skipped unless --full is given. --limit caps the number of
+// sitemaps fetched.
+func runSitemaps(a *app) error {
+	sitemapScraper := scraper.NewSitemapScraper(a.db, a.site)
+
+	var sitemaps []string
+	if noDiscover {
+		sitemaps = sitemapScraper.SitemapURLs()
+	} else {
+		var err error
+		if sitemaps, err = sitemapScraper.ArticleSitemapURLs(); err != nil {
+			return err
+		}
+	}
+	if a.opts.limit > 0 && a.opts.limit < len(sitemaps) {
+		sitemaps = sitemaps[:a.opts.limit]
+	}
+	return sitemapScraper.ScrapeSitemaps(sitemaps, fullScrape)
+}
@@ -0,0 +1,11 @@
+package main
+
+import "github.com/jerryagenyi/go_ng_news_scraper/internal/scraper"
+
+// runTags imports the site's tag sitemaps. Articles create any tag they
+// link to that is missing, so this step only fills in tags no scraped
+// article uses yet and their archive URLs.
+func runTags(a *app) error {
+	tagScraper := scraper.NewTagScraper(a.db, a.site)
+	return tagScraper.ScrapeTags()
+}
@@ -2,10 +2,12 @@ module github.com/jerryagenyi/go_ng_news_scraper
 
 go 1.24.0
 
-require github.com/lib/pq v1.10.9
-
 require (
-	github.com/PuerkitoBio/goquery v1.10.2 // indirect
-	github.com/andybalholm/cascadia v1.3.3 // indirect
-	golang.org/x/net v0.35.0 // indirect
+	github.com/PuerkitoBio/goquery v1.10.2
+	github.com/lib/pq v1.10.9
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.35.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/andybalholm/cascadia v1.3.3 // indirect
@@ -0,0 +1,104 @@
+// Package archive stores raw fetched pages in a content-addressed directory so
+// articles can be re-extracted later without going back to the network. Each
+// body is gzipped and written once under its sha256, fanned out into two
+// levels of subdirectories; the go_raw_pages table maps URLs to those hashes.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Store is a content-addressed directory of gzipped page bodies.
+type Store struct {
+	dir string
+}
+
+// Open returns the store rooted at dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Hash returns the hex sha256 under which body is stored.
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns where the body with the given hash lives, e.g.
+// ab/cd/abcd….html.gz.
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash[2:4], hash+".html.gz")
+}
+
+// Put stores body and returns its hash. Bodies already in the store are not
+// written again. Files are written to a temporary name and renamed into
+// place, so concurrent writers never expose a partial file.
+func (s *Store) Put(body []byte) (string, error) {
+	hash := Hash(body)
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if _, err := gz.Write(body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store archive file: %w", err)
+	}
+	return hash, nil
+}
+
+// Get returns the body stored under hash and checks it still matches.
+func (s *Store) Get(hash string) ([]byte, error) {
+	if len(hash) != sha256.Size*2 {
+		return nil, fmt.Errorf("invalid archive hash %q", hash)
+	}
+	f, err := os.Open(s.path(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archived page: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived page: %w", err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, gz); err != nil {
+		return nil, fmt.Errorf("failed to read archived page: %w", err)
+	}
+	if Hash(buf.Bytes()) != hash {
+		return nil, errors.New("archived page is corrupt: hash mismatch")
+	}
+	return buf.Bytes(), nil
+}
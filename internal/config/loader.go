@@ -0,0 +1,263 @@
+// Package config provides configuration structures and values for the Nigerian news scraper.
+// This file loads the database and website configuration from a YAML or JSON
+// file, applies environment variable overrides and validates the result.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables consulted by Load.
+const (
+	EnvConfigPath = "NG_NEWS_CONFIG" // Path of the config file when none is given
+	EnvDBHost     = "NG_NEWS_DB_HOST"
+	EnvDBPort     = "NG_NEWS_DB_PORT"
+	EnvDBUser     = "NG_NEWS_DB_USER"
+	EnvDBPassword = "NG_NEWS_DB_PASSWORD"
+	EnvDBName     = "NG_NEWS_DB_NAME"
+	EnvDBSSLMode  = "NG_NEWS_DB_SSLMODE"
+)
+
+// Category structures accepted in WebsiteConfig.CategoryStructure.
+const (
+	CategoryHierarchical = "hierarchical"
+	CategoryFlat         = "flat"
+)
+
+// File mirrors the layout of a configuration file on disk.
+//
+//	database:
+//	  host: localhost
+//	  dbname: ng_news
+//	websites:
+//	  - id: 1
+//	    name: Blue Print
+//	    base_url: https://blueprint.ng
+//	    ...
+type File struct {
+	Database Config          `yaml:"database" json:"database"`
+	Websites []WebsiteConfig `yaml:"websites" json:"websites"`
+}
+
+// Settings is the fully resolved configuration used by the commands.
+type Settings struct {
+	DB       Config                // Database connection parameters
+	Websites map[int]WebsiteConfig // Website configurations keyed by ID
+}
+
+// Load reads the configuration file at path, falling back to the
+// NG_NEWS_CONFIG environment variable and then to the built-in DBConfig and
+// Websites values when no file is given. Database fields present in the file
+// replace the defaults, NG_NEWS_DB_* environment variables override both, and
+// every website is validated before the settings are returned.
+func Load(path string) (*Settings, error) {
+	if path == "" {
+		path = os.Getenv(EnvConfigPath)
+	}
+
+	settings := &Settings{DB: DBConfig, Websites: make(map[int]WebsiteConfig)}
+
+	if path == "" {
+		for id, site := range Websites {
+			settings.Websites[id] = site
+		}
+	} else {
+		file, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		mergeDB(&settings.DB, file.Database)
+		for _, site := range file.Websites {
+			if _, dup := settings.Websites[site.ID]; dup {
+				return nil, fmt.Errorf("duplicate website id %d in %s", site.ID, path)
+			}
+			settings.Websites[site.ID] = site
+		}
+	}
+
+	if err := applyEnv(&settings.DB); err != nil {
+		return nil, err
+	}
+
+	if len(settings.Websites) == 0 {
+		return nil, fmt.Errorf("no websites configured")
+	}
+	for id, site := range settings.Websites {
+		applyDefaults(&site)
+		if err := site.Validate(); err != nil {
+			return nil, err
+		}
+		settings.Websites[id] = site
+	}
+
+	return settings, nil
+}
+
+// readFile decodes a configuration file, choosing JSON or YAML by extension.
+func readFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	default:
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// mergeDB copies every non-zero field of src into dst.
+func mergeDB(dst *Config, src Config) {
+	if src.Host != "" {
+		dst.Host = src.Host
+	}
+	if src.Port != 0 {
+		dst.Port = src.Port
+	}
+	if src.User != "" {
+		dst.User = src.User
+	}
+	if src.Password != "" {
+		dst.Password = src.Password
+	}
+	if src.DBName != "" {
+		dst.DBName = src.DBName
+	}
+	if src.SSLMode != "" {
+		dst.SSLMode = src.SSLMode
+	}
+}
+
+// applyEnv overrides database fields with NG_NEWS_DB_* environment variables.
+func applyEnv(db *Config) error {
+	if v, ok := os.LookupEnv(EnvDBHost); ok {
+		db.Host = v
+	}
+	if v, ok := os.LookupEnv(EnvDBPort); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", EnvDBPort, v, err)
+		}
+		db.Port = port
+	}
+	if v, ok := os.LookupEnv(EnvDBUser); ok {
+		db.User = v
+	}
+	if v, ok := os.LookupEnv(EnvDBPassword); ok {
+		db.Password = v
+	}
+	if v, ok := os.LookupEnv(EnvDBName); ok {
+		db.DBName = v
+	}
+	if v, ok := os.LookupEnv(EnvDBSSLMode); ok {
+		db.SSLMode = v
+	}
+	return nil
+}
+
+// applyDefaults fills in processing limits that were left unset.
+func applyDefaults(site *WebsiteConfig) {
+	if site.MaxWorkers <= 0 {
+		site.MaxWorkers = 1
+	}
+	if site.BatchSize <= 0 {
+		site.BatchSize = 100
+	}
+	if site.Timeout <= 0 {
+		site.Timeout = 30
+	}
+	if site.MaxRetries <= 0 {
+		site.MaxRetries = 3
+	}
+	if site.RequestsPerSecond <= 0 {
+		site.RequestsPerSecond = 1
+	}
+	if site.Burst <= 0 {
+		site.Burst = site.MaxWorkers
+	}
+	if site.CategoryStructure == "" {
+		site.CategoryStructure = CategoryHierarchical
+	}
+	if site.MinContentLength == 0 {
+		site.MinContentLength = 200
+	}
+	if len(site.Selectors.Tags) == 0 {
+		site.Selectors.Tags = []string{`a[rel="tag"]`}
+	}
+	if site.ThumbnailWidth <= 0 {
+		site.ThumbnailWidth = 320
+	}
+}
+
+// Validate reports the first missing or inconsistent required field.
+func (w WebsiteConfig) Validate() error {
+	if w.ID <= 0 {
+		return fmt.Errorf("website %q: id must be positive", w.Name)
+	}
+	if w.BaseURL == "" {
+		return fmt.Errorf("website %d: base_url is required", w.ID)
+	}
+	if u, err := url.Parse(w.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("website %d: base_url %q is not an absolute URL", w.ID, w.BaseURL)
+	}
+	// Sitemaps are normally discovered from robots.txt; the numbered format
+	// is an optional fallback, but must be usable when given.
+	if w.SitemapFormat != "" {
+		if !strings.Contains(w.SitemapFormat, "%d") {
+			return fmt.Errorf("website %d: sitemap_format %q must contain %%d", w.ID, w.SitemapFormat)
+		}
+		if w.StartIndex < 0 || w.EndIndex < w.StartIndex {
+			return fmt.Errorf("website %d: invalid sitemap index range %d-%d", w.ID, w.StartIndex, w.EndIndex)
+		}
+	}
+	switch w.CategoryStructure {
+	case CategoryHierarchical, CategoryFlat:
+	default:
+		return fmt.Errorf("website %d: category_structure must be %q or %q, got %q",
+			w.ID, CategoryHierarchical, CategoryFlat, w.CategoryStructure)
+	}
+	return nil
+}
+
+// Website looks up a website by numeric ID or by case-insensitive name.
+// An empty key selects the only configured website, if there is exactly one.
+func (s *Settings) Website(key string) (WebsiteConfig, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		if len(s.Websites) == 1 {
+			for _, site := range s.Websites {
+				return site, nil
+			}
+		}
+		return WebsiteConfig{}, fmt.Errorf("%d websites configured, choose one with --site", len(s.Websites))
+	}
+
+	if id, err := strconv.Atoi(key); err == nil {
+		if site, ok := s.Websites[id]; ok {
+			return site, nil
+		}
+		return WebsiteConfig{}, fmt.Errorf("no website with id %d", id)
+	}
+
+	for _, site := range s.Websites {
+		if strings.EqualFold(site.Name, key) {
+			return site, nil
+		}
+	}
+	return WebsiteConfig{}, fmt.Errorf("no website named %q", key)
+}
@@ -3,55 +3,99 @@
 // sitemap locations, processing parameters, and category structure definitions.
 package config
 
+import "fmt"
+
 // Config holds database connection parameters.
 // It provides the necessary information to establish a connection
 // with the PostgreSQL database.
 type Config struct {
-	Host     string // Database server hostname
-	Port     int    // Database server port
-	User     string // Database user
-	Password string // Database password
-	DBName   string // Target database name
+	Host     string `yaml:"host" json:"host"`         // Database server hostname
+	Port     int    `yaml:"port" json:"port"`         // Database server port
+	User     string `yaml:"user" json:"user"`         // Database user
+	Password string `yaml:"password" json:"password"` // Database password
+	DBName   string `yaml:"dbname" json:"dbname"`     // Target database name
+	SSLMode  string `yaml:"sslmode" json:"sslmode"`   // PostgreSQL sslmode, "disable" when empty
+}
+
+// DSN returns the lib/pq connection string for the configuration.
+func (c Config) DSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, sslMode)
 }
 
 // Default database configuration settings.
-// These values are used when no custom configuration is provided.
+// These values are used when no custom configuration is provided. The password
+// is intentionally empty; supply it through NG_NEWS_DB_PASSWORD or a config file.
 var DBConfig = Config{
-	Host:     "localhost",
-	Port:     5432,
-	User:     "postgres",
-	Password: "naija1",
-	DBName:   "ng_news",
+	Host:   "localhost",
+	Port:   5432,
+	User:   "postgres",
+	DBName: "ng_news",
 }
 
 // WebsiteConfig defines the structure for website-specific settings.
 // It contains all necessary parameters for scraping a specific news website,
 // including sitemap locations, processing limits, and timing configurations.
 type WebsiteConfig struct {
-	ID                 int    // Unique identifier matching go_websites table
-	Name               string // Display name of the website
-	BaseURL            string // Root URL of the website
-	SitemapFormat      string // Format string for sitemap URLs
-	StartIndex         int    // First sitemap index
-	EndIndex           int    // Last sitemap index
-	MaxWorkers         int    // Maximum concurrent scraping workers
-	BatchSize          int    // Number of URLs to process in one batch
-	Timeout            int    // Request timeout in seconds
-	RetryDelay         int    // Delay between retries in seconds
-	MaxRetries         int    // Maximum number of retry attempts
-	CategorySitemapURL string // URL of the category sitemap
-	CategoryStructure  string // Category organization: "hierarchical" or "flat"
+	ID                  int       `yaml:"id" json:"id"`                                     // Unique identifier matching go_websites table
+	Name                string    `yaml:"name" json:"name"`                                 // Display name of the website
+	BaseURL             string    `yaml:"base_url" json:"base_url"`                         // Root URL of the website
+	TrailingSlash       bool      `yaml:"trailing_slash" json:"trailing_slash"`             // Permalinks end in "/", so URL keys add one to extensionless paths
+	SitemapFormat       string    `yaml:"sitemap_format" json:"sitemap_format"`             // Fallback format string for sitemap URLs
+	FirstSitemapURL     string    `yaml:"first_sitemap_url" json:"first_sitemap_url"`       // Unnumbered URL used in place of StartIndex, if any
+	StartIndex          int       `yaml:"start_index" json:"start_index"`                   // First sitemap index for SitemapFormat
+	EndIndex            int       `yaml:"end_index" json:"end_index"`                       // Last sitemap index for SitemapFormat
+	MaxWorkers          int       `yaml:"max_workers" json:"max_workers"`                   // Maximum concurrent scraping workers
+	BatchSize           int       `yaml:"batch_size" json:"batch_size"`                     // Number of URLs to process in one batch
+	Timeout             int       `yaml:"timeout" json:"timeout"`                           // Request timeout in seconds
+	RetryDelay          int       `yaml:"retry_delay" json:"retry_delay"`                   // Delay between retries in seconds
+	MaxRetries          int       `yaml:"max_retries" json:"max_retries"`                   // Maximum number of retry attempts
+	RequestsPerSecond   float64   `yaml:"requests_per_second" json:"requests_per_second"`   // Sustained request rate per host across all workers
+	Burst               int       `yaml:"burst" json:"burst"`                               // Requests allowed back to back before pacing applies
+	UserAgent           string    `yaml:"user_agent" json:"user_agent"`                     // User-Agent sent with requests and matched against robots.txt
+	ArchiveDir          string    `yaml:"archive_dir" json:"archive_dir"`                   // Directory for raw article HTML, archiving disabled when empty
+	MinContentLength    int       `yaml:"min_content_length" json:"min_content_length"`     // Shortest article body accepted, 200 characters when zero, disabled when negative
+	ThumbnailDir        string    `yaml:"thumbnail_dir" json:"thumbnail_dir"`               // Directory for article image thumbnails, downloading disabled when empty
+	ThumbnailWidth      int       `yaml:"thumbnail_width" json:"thumbnail_width"`           // Width of stored thumbnails in pixels, 320 when zero
+	MaxSitemapBytes     int64     `yaml:"max_sitemap_bytes" json:"max_sitemap_bytes"`       // Largest uncompressed sitemap accepted, 50MB when zero
+	MaxSitemapURLs      int       `yaml:"max_sitemap_urls" json:"max_sitemap_urls"`         // Most entries accepted per sitemap, 50,000 when zero
+	CategorySitemapURL  string    `yaml:"category_sitemap_url" json:"category_sitemap_url"` // URL of the category sitemap, discovered when empty
+	TagSitemapURL       string    `yaml:"tag_sitemap_url" json:"tag_sitemap_url"`           // URL of the tag sitemap, all discovered tag sitemaps when empty
+	CategoryStructure   string    `yaml:"category_structure" json:"category_structure"`     // Category organization: "hierarchical" or "flat"
+	CategoryBreadcrumbs bool      `yaml:"category_breadcrumbs" json:"category_breadcrumbs"` // Read parents of non-nested categories from the breadcrumbs on their pages
+	Selectors           Selectors `yaml:"selectors" json:"selectors"`                       // CSS selectors used to extract article fields
+}
+
+// Selectors holds the CSS selectors used to extract article fields from a
+// website's article pages. Each field lists one or more selectors in order of
+// preference; the scraper uses the first one that matches anything, so themes
+// that vary between sections or over time can be covered by adding fallbacks.
+type Selectors struct {
+	Title       []string `yaml:"title" json:"title"`             // Article headline
+	Categories  []string `yaml:"categories" json:"categories"`   // Category links; the href is used to derive the slug
+	Tags        []string `yaml:"tags" json:"tags"`               // Tag links; the href is used to derive the slug, a[rel="tag"] when empty
+	Breadcrumbs []string `yaml:"breadcrumbs" json:"breadcrumbs"` // Breadcrumb links on category pages, common theme markup when empty
+	Author      []string `yaml:"author" json:"author"`           // Author byline
+	Published   []string `yaml:"published" json:"published"`     // Publication date, read from the datetime attribute or the element text
+	Updated     []string `yaml:"updated" json:"updated"`         // Last modification date, read from the datetime attribute or the element text
+	Content     []string `yaml:"content" json:"content"`         // Body paragraphs, joined with blank lines
 }
 
 // Websites maps website IDs to their corresponding configurations.
-// This map serves as the central repository of scraping configurations
-// for all supported Nigerian news websites.
+// This map serves as the built-in set of scraping configurations used when
+// no sites file is supplied to Load.
 var Websites = map[int]WebsiteConfig{
 	1: {
 		ID:                 1,
 		Name:               "Blue Print",
 		BaseURL:            "https://blueprint.ng",
+		TrailingSlash:      true,
 		SitemapFormat:      "https://blueprint.ng/post-sitemap%d.xml",
+		FirstSitemapURL:    "https://blueprint.ng/post-sitemap.xml",
 		StartIndex:         1,
 		EndIndex:           221,
 		MaxWorkers:         3,   // Optimized based on server response
@@ -59,8 +103,19 @@ var Websites = map[int]WebsiteConfig{
 		Timeout:            90,  // 90 seconds to handle slow responses
 		RetryDelay:         5,   // 5 seconds between retries
 		MaxRetries:         3,   // Maximum 3 retry attempts
+		RequestsPerSecond:  0.5, // Shared by all workers; slows further after sitemap 140
+		Burst:              3,   // One request per worker
 		CategorySitemapURL: "https://blueprint.ng/category-sitemap.xml",
 		CategoryStructure:  "hierarchical",
+		Selectors: Selectors{
+			Title:      []string{"h1.entry-title"},
+			Categories: []string{"div.cat-links a"},
+			Tags:       []string{"span.tags-links a", `a[rel="tag"]`},
+			Author:     []string{"span.author.vcard a", "span.author.vcard"},
+			Published:  []string{"time.entry-date.published"},
+			Updated:    []string{"time.updated"},
+			Content:    []string{"div.entry-content > p", "article div.entry-content p"},
+		},
 	},
-	// Additional websites can be added here with their specific configurations
+	// Additional websites can be added here or, preferably, in a sites file
 }
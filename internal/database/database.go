@@ -0,0 +1,30 @@
+// Package database provides the shared PostgreSQL bootstrap used by every
+// scraper command, so connection handling lives in one place.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+// Open connects to the database described by cfg and verifies the
+// connection with a ping before returning it.
+func Open(cfg config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database %s on %s:%d: %w",
+			cfg.DBName, cfg.Host, cfg.Port, err)
+	}
+
+	log.Println("Successfully connected to database")
+	return db, nil
+}
@@ -0,0 +1,320 @@
+// Package database provides the shared PostgreSQL bootstrap used by every
+// scraper command. This file holds the schema migrations that extend the
+// go_* tables; they are applied in order and recorded in go_schema_migrations.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migrationLockID is the pg_advisory_xact_lock key serialising concurrent
+// migrators, e.g. several scraper processes starting together.
+const migrationLockID = 7264001
+
+// migration is a schema change applied exactly once.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrations must only ever be appended to; applied versions are never re-run.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "sitemap news and image extensions",
+		sql: `
+			ALTER TABLE go_sitemaps
+				ADD COLUMN IF NOT EXISTS news_publication_date TIMESTAMPTZ,
+				ADD COLUMN IF NOT EXISTS news_title TEXT,
+				ADD COLUMN IF NOT EXISTS news_keywords TEXT[],
+				ADD COLUMN IF NOT EXISTS image_urls TEXT[];
+			CREATE INDEX IF NOT EXISTS go_sitemaps_news_publication_date_idx
+				ON go_sitemaps (website_id, news_publication_date DESC);
+		`,
+	},
+	{
+		version: 2,
+		name:    "article last scraped time",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS last_scraped_at TIMESTAMPTZ;
+		`,
+	},
+	{
+		version: 3,
+		name:    "scrape job queue",
+		sql: `
+			CREATE TABLE IF NOT EXISTS go_scrape_jobs (
+				id              BIGSERIAL PRIMARY KEY,
+				website_id      INT NOT NULL,
+				url             TEXT NOT NULL,
+				state           TEXT NOT NULL DEFAULT 'pending'
+					CHECK (state IN ('pending', 'in_progress', 'done', 'failed')),
+				attempts        INT NOT NULL DEFAULT 0,
+				last_error      TEXT,
+				priority_at     TIMESTAMPTZ,
+				next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				claimed_at      TIMESTAMPTZ,
+				created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (website_id, url)
+			);
+			CREATE INDEX IF NOT EXISTS go_scrape_jobs_claim_idx
+				ON go_scrape_jobs (website_id, state, next_attempt_at);
+		`,
+	},
+	{
+		version: 4,
+		name:    "skipped scrape jobs",
+		sql: `
+			ALTER TABLE go_scrape_jobs
+				DROP CONSTRAINT IF EXISTS go_scrape_jobs_state_check;
+			ALTER TABLE go_scrape_jobs
+				ADD CONSTRAINT go_scrape_jobs_state_check
+				CHECK (state IN ('pending', 'in_progress', 'done', 'failed', 'skipped'));
+		`,
+	},
+	{
+		version: 5,
+		name:    "http cache validators",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS etag TEXT,
+				ADD COLUMN IF NOT EXISTS last_modified TEXT;
+			CREATE TABLE IF NOT EXISTS go_sitemap_files (
+				website_id      INT NOT NULL,
+				url             TEXT NOT NULL,
+				etag            TEXT,
+				last_modified   TEXT,
+				url_count       INT NOT NULL DEFAULT 0,
+				last_fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (website_id, url)
+			);
+		`,
+	},
+	{
+		version: 6,
+		name:    "raw page archive index",
+		sql: `
+			CREATE TABLE IF NOT EXISTS go_raw_pages (
+				id            BIGSERIAL PRIMARY KEY,
+				website_id    INT NOT NULL,
+				url           TEXT NOT NULL,
+				sha256        TEXT NOT NULL,
+				status_code   INT,
+				content_type  TEXT,
+				etag          TEXT,
+				last_modified TEXT,
+				fetched_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (website_id, url, sha256)
+			);
+			CREATE INDEX IF NOT EXISTS go_raw_pages_url_idx
+				ON go_raw_pages (website_id, url, fetched_at DESC);
+		`,
+	},
+	{
+		version: 7,
+		name:    "article fetch metadata",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS status_code INT,
+				ADD COLUMN IF NOT EXISTS final_url TEXT,
+				ADD COLUMN IF NOT EXISTS redirect_chain TEXT[],
+				ADD COLUMN IF NOT EXISTS canonical_url TEXT,
+				ADD COLUMN IF NOT EXISTS content_type TEXT,
+				ADD COLUMN IF NOT EXISTS fetch_latency_ms INT;
+		`,
+	},
+	{
+		version: 8,
+		name:    "sitemap canonical url",
+		sql: `
+			ALTER TABLE go_sitemaps
+				ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+		`,
+	},
+	{
+		version: 9,
+		name:    "article structured metadata",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS section TEXT,
+				ADD COLUMN IF NOT EXISTS keywords TEXT[],
+				ADD COLUMN IF NOT EXISTS image_url TEXT,
+				ADD COLUMN IF NOT EXISTS field_sources JSONB;
+		`,
+	},
+	{
+		version: 10,
+		name:    "article extraction failures",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS extraction_failed BOOLEAN NOT NULL DEFAULT false;
+		`,
+	},
+	{
+		version: 11,
+		name:    "article markdown",
+		sql: `
+			ALTER TABLE go_articles
+				ADD COLUMN IF NOT EXISTS content_markdown TEXT;
+		`,
+	},
+	{
+		version: 12,
+		name:    "article media",
+		sql: `
+			CREATE TABLE IF NOT EXISTS go_article_media (
+				id               BIGSERIAL PRIMARY KEY,
+				article_id       INT NOT NULL REFERENCES go_articles (id) ON DELETE CASCADE,
+				url              TEXT NOT NULL,
+				position         INT NOT NULL,
+				featured         BOOLEAN NOT NULL DEFAULT false,
+				alt              TEXT,
+				caption          TEXT,
+				credit           TEXT,
+				width            INT,
+				height           INT,
+				source           TEXT,
+				thumbnail_sha256 TEXT,
+				created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (article_id, url)
+			);
+		`,
+	},
+	{
+		version: 13,
+		name:    "date parse warnings",
+		sql: `
+			ALTER TABLE go_articles
+				ALTER COLUMN publish_date DROP NOT NULL,
+				ALTER COLUMN last_updated DROP NOT NULL,
+				ADD COLUMN IF NOT EXISTS parse_warnings TEXT[];
+			ALTER TABLE go_sitemaps
+				ADD COLUMN IF NOT EXISTS parse_warnings TEXT[];
+
+			-- Unparsed dates used to be stored as the zero time.
+			UPDATE go_articles SET publish_date = NULL WHERE publish_date < '1900-01-01';
+			UPDATE go_articles SET last_updated = NULL WHERE last_updated < '1900-01-01';
+		`,
+	},
+	{
+		version: 14,
+		name:    "authors",
+		sql: `
+			CREATE TABLE IF NOT EXISTS go_authors (
+				id          SERIAL PRIMARY KEY,
+				website_id  INT NOT NULL,
+				name        TEXT NOT NULL,
+				slug        TEXT NOT NULL,
+				profile_url TEXT,
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (website_id, slug)
+			);
+
+			CREATE TABLE IF NOT EXISTS go_article_authors (
+				article_id INT NOT NULL REFERENCES go_articles (id) ON DELETE CASCADE,
+				author_id  INT NOT NULL REFERENCES go_authors (id) ON DELETE CASCADE,
+				position   INT NOT NULL,
+				PRIMARY KEY (article_id, author_id)
+			);
+			CREATE INDEX IF NOT EXISTS go_article_authors_author_idx
+				ON go_article_authors (author_id);
+		`,
+	},
+	{
+		version: 15,
+		name:    "tags",
+		sql: `
+			CREATE TABLE IF NOT EXISTS go_tags (
+				id         SERIAL PRIMARY KEY,
+				website_id INT NOT NULL,
+				name       TEXT NOT NULL,
+				slug       TEXT NOT NULL,
+				url        TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (website_id, slug)
+			);
+
+			CREATE TABLE IF NOT EXISTS go_article_tags (
+				article_id INT NOT NULL REFERENCES go_articles (id) ON DELETE CASCADE,
+				tag_id     INT NOT NULL REFERENCES go_tags (id) ON DELETE CASCADE,
+				PRIMARY KEY (article_id, tag_id)
+			);
+			CREATE INDEX IF NOT EXISTS go_article_tags_tag_idx
+				ON go_article_tags (tag_id);
+		`,
+	},
+	{
+		version: 16,
+		name:    "categories discovered from articles",
+		sql: `
+			ALTER TABLE go_categories
+				ADD COLUMN IF NOT EXISTS discovered_from_article BOOLEAN NOT NULL DEFAULT false;
+		`,
+	},
+}
+
+// Migrate applies every migration that has not been recorded yet.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS go_schema_migrations (
+			version    INT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create go_schema_migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	var applied bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM go_schema_migrations WHERE version = $1)
+	`, m.version).Scan(&applied)
+	if err != nil {
+		return fmt.Errorf("failed to check migration state: %w", err)
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO go_schema_migrations (version, name) VALUES ($1, $2)
+	`, m.version, m.name); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+	log.Printf("Applied migration %d: %s", m.version, m.name)
+	return nil
+}
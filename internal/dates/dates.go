@@ -0,0 +1,179 @@
+// Package dates parses the publication and modification dates found in
+// sitemaps and article pages. Besides the W3C and RFC formats used by
+// metadata it understands the forms Nigerian news themes print for readers:
+// "January 5, 2024", day-first numeric dates such as 05/01/2024, and
+// relative times such as "2 hours ago". Values without a time zone are read
+// as West Africa Time (Africa/Lagos) and every result is returned in UTC.
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnrecognised is returned for values in none of the supported formats.
+var ErrUnrecognised = errors.New("unrecognised date")
+
+// Lagos is the zone assumed for dates that do not carry one. Nigeria has
+// kept UTC+1 without daylight saving since 1964, so the fixed zone is used
+// when the system has no time zone database.
+var Lagos = loadLagos()
+
+func loadLagos() *time.Location {
+	if loc, err := time.LoadLocation("Africa/Lagos"); err == nil {
+		return loc
+	}
+	return time.FixedZone("WAT", 60*60)
+}
+
+// layouts are tried in order after the value has been normalised. Layouts
+// without a zone are interpreted in Lagos; zone abbreviations such as WAT
+// take their offset from it too.
+var layouts = []string{
+	// W3C and RFC 3339 variants
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+
+	// RFC 1123 and friends, as sent in HTTP headers and RSS
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+
+	// Month names, month first and day first
+	"January 2 2006 3:04 pm",
+	"January 2 2006 3:04pm",
+	"January 2 2006 15:04",
+	"January 2 2006",
+	"Jan 2 2006 3:04 pm",
+	"Jan 2 2006 3:04pm",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006",
+	"2 January 2006 3:04 pm",
+	"2 January 2006 3:04pm",
+	"2 January 2006 15:04",
+	"2 January 2006",
+	"2 Jan 2006 3:04 pm",
+	"2 Jan 2006 3:04pm",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+
+	// Day-first numeric dates, after separators are folded to "/"
+	"2/1/2006 3:04 pm",
+	"2/1/2006 3:04pm",
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2/1/2006",
+}
+
+var (
+	// isoDate matches values that need no cleaning.
+	isoDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	// weekdayPrefix matches a leading day name, as in "Monday, January 5".
+	weekdayPrefix = regexp.MustCompile(`(?i)^(mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)[a-z]*\.?,?\s+`)
+	// ordinalSuffix matches the "th" of "5th January".
+	ordinalSuffix = regexp.MustCompile(`(?i)\b(\d{1,2})(st|nd|rd|th)\b`)
+	// numericDate matches day-first dates written with dots or dashes.
+	numericDate = regexp.MustCompile(`^(\d{1,2})[.\-](\d{1,2})[.\-](\d{4})\b`)
+	// relative matches "2 hours ago", "an hour ago" and abbreviations such
+	// as "5 mins ago".
+	relative = regexp.MustCompile(`(?i)^(\d+|an?|one)\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?|days?|weeks?|wks?|months?|years?|yrs?)\s+ago$`)
+)
+
+// Parse reads value as a date. Relative values such as "3 days ago" or
+// "yesterday" are resolved against now, which should be the time the page
+// was fetched.
+func Parse(value string, now time.Time) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%w: empty value", ErrUnrecognised)
+	}
+
+	// The value is tried as given first, so formats such as RFC 1123 keep
+	// the weekday and commas their layouts expect.
+	for _, candidate := range []string{value, normalise(value)} {
+		if t, ok := parseRelative(candidate, now); ok {
+			return t.UTC(), nil
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, candidate, Lagos); err == nil {
+				return t.UTC(), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w %q", ErrUnrecognised, value)
+}
+
+// normalise strips the decoration themes put around dates, so the layouts
+// only need to cover the bare forms.
+func normalise(value string) string {
+	for _, prefix := range []string{"Published on ", "Published ", "Updated on ", "Updated ", "Posted on ", "Posted "} {
+		if len(value) > len(prefix) && strings.EqualFold(value[:len(prefix)], prefix) {
+			value = value[len(prefix):]
+			break
+		}
+	}
+	if isoDate.MatchString(value) {
+		return value
+	}
+
+	value = weekdayPrefix.ReplaceAllString(value, "")
+	value = ordinalSuffix.ReplaceAllString(value, "$1")
+	value = numericDate.ReplaceAllString(value, "$1/$2/$3")
+	value = strings.NewReplacer(",", " ", " at ", " ", " of ", " ", "Sept ", "Sep ", "a.m.", "am", "p.m.", "pm").Replace(value)
+	value = strings.Join(strings.Fields(value), " ")
+	return strings.NewReplacer(" AM", " am", " PM", " pm", "AM", "am", "PM", "pm").Replace(value)
+}
+
+// parseRelative resolves "just now", "today", "yesterday" and "N units ago".
+func parseRelative(value string, now time.Time) (time.Time, bool) {
+	switch strings.ToLower(value) {
+	case "just now", "now", "today":
+		return now, true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	m := relative.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		n = 1 // "a", "an", "one"
+	}
+	switch unit := strings.ToLower(m[2]); {
+	case strings.HasPrefix(unit, "s"):
+		return now.Add(-time.Duration(n) * time.Second), true
+	case strings.HasPrefix(unit, "mi"):
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case strings.HasPrefix(unit, "h"):
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case strings.HasPrefix(unit, "d"):
+		return now.AddDate(0, 0, -n), true
+	case strings.HasPrefix(unit, "w"):
+		return now.AddDate(0, 0, -7*n), true
+	case strings.HasPrefix(unit, "mo"):
+		return now.AddDate(0, -n, 0), true
+	default:
+		return now.AddDate(-n, 0, 0), true
+	}
+}
@@ -0,0 +1,111 @@
+package dates
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		// W3C and RFC 3339, with and without a zone
+		{"rfc3339 utc", "2024-01-05T10:00:00Z", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"rfc3339 offset", "2024-01-05T10:00:00+01:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"rfc3339 fraction", "2024-01-05T10:00:00.123+01:00", time.Date(2024, 1, 5, 9, 0, 0, 123000000, time.UTC)},
+		{"w3c minutes", "2024-01-05T10:00+01:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"compact offset", "2024-01-05T10:00:00+0100", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"no zone", "2024-01-05T10:00:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"no zone minutes", "2024-01-05T10:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"space offset", "2024-01-05 10:00:00+00:00", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"space numeric zone", "2024-01-05 10:00:00 +0000", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"space zone name", "2024-01-05 10:00:00 WAT", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"space no zone", "2024-01-05 10:00:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"space no seconds", "2024-01-05 10:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"date only", "2024-01-05", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"prefixed iso", "Updated 2024-01-05", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+
+		// HTTP and RSS formats
+		{"rfc1123z", "Fri, 05 Jan 2024 10:00:00 +0000", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"rfc1123", "Fri, 05 Jan 2024 10:00:00 GMT", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"rfc1123 zone name", "Fri, 5 Jan 2024 10:00:00 WAT", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"rfc1123 single digit day", "Fri, 5 Jan 2024 10:00:00 +0100", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"rfc850", "Friday, 05-Jan-24 10:00:00 GMT", time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"rfc822z", "05 Jan 24 10:00 +0100", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"ansic", "Fri Jan  5 10:00:00 2024", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+
+		// Month names as printed by themes, read as Lagos time
+		{"month first", "January 5, 2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"month first time", "January 5, 2024 at 3:04 pm", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+		{"month first upper meridiem", "January 5, 2024 3:04PM", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+		{"month first dotted meridiem", "January 5, 2024 3:04 p.m.", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+		{"month first 24 hour", "January 5, 2024 15:04", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+		{"short month", "Jan 5, 2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"sept", "Sept 5, 2024", time.Date(2024, 9, 4, 23, 0, 0, 0, time.UTC)},
+		{"weekday and ordinal", "Friday, January 5th, 2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"day first", "5 January 2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"day first ordinal", "5th of January 2024 10:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"day first short", "5 Jan 2024 3:04pm", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+		{"published prefix", "Published on January 5, 2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+
+		// Day-first numeric dates
+		{"slashes", "05/01/2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"day over twelve", "13/01/2024", time.Date(2024, 1, 12, 23, 0, 0, 0, time.UTC)},
+		{"dots", "05.01.2024", time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)},
+		{"dashes", "05-01-2024 10:00", time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"slashes meridiem", "5/1/2024 3:04 PM", time.Date(2024, 1, 5, 14, 4, 0, 0, time.UTC)},
+
+		// Relative to now
+		{"just now", "just now", now},
+		{"yesterday", "Yesterday", now.AddDate(0, 0, -1)},
+		{"hours", "2 hours ago", now.Add(-2 * time.Hour)},
+		{"an hour", "an hour ago", now.Add(-time.Hour)},
+		{"mins", "5 mins ago", now.Add(-5 * time.Minute)},
+		{"days", "3 days ago", now.AddDate(0, 0, -3)},
+		{"weeks", "1 week ago", now.AddDate(0, 0, -7)},
+		{"months", "2 months ago", now.AddDate(0, -2, 0)},
+		{"years", "a year ago", now.AddDate(-1, 0, 0)},
+		{"extra whitespace", "  2   hours\tago ", now.Add(-2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{
+		"",
+		"   ",
+		"garbage",
+		"0000-00-00 00:00:00",
+		"2024-13-45",
+		"32/01/2024",
+		"January 2024",
+		"2 fortnights ago",
+	} {
+		t.Run(value, func(t *testing.T) {
+			got, err := Parse(value, now)
+			if !errors.Is(err, ErrUnrecognised) {
+				t.Fatalf("Parse(%q) = %v, %v; want ErrUnrecognised", value, got, err)
+			}
+			if !got.IsZero() {
+				t.Errorf("Parse(%q) = %v, want zero time", value, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Package fetch provides the HTTP fetcher shared by the sitemap, category and
+// article scrapers. It applies a website's timeout and retry settings, retries
+// rate-limited and server-error responses with exponential backoff and jitter,
+// honours Retry-After, and reports failures as typed errors so callers can tell
+// pages that are gone from failures worth retrying later. Conditional requests
+// built from stored ETag and Last-Modified validators report an unchanged page
+// as ErrNotModified.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+// maxRetryAfter caps how long a Retry-After header can make us wait.
+const maxRetryAfter = 5 * time.Minute
+
+// DefaultUserAgent identifies the scraper when a website sets no UserAgent.
+const DefaultUserAgent = "ng-news-scraper/1.0 (+https://github.com/jerryagenyi/go_ng_news_scraper)"
+
+// ErrNotModified is returned by GetIfModified when the server answers 304
+// Not Modified, i.e. the page is unchanged since the validators were stored.
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the cache validators of an earlier response, sent back as
+// If-None-Match and If-Modified-Since.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ValidatorsOf returns the ETag and Last-Modified headers of resp.
+func ValidatorsOf(resp *http.Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// PermanentError reports a request that retrying will not fix: 404 Not
+// Found, 410 Gone and other client errors, or a malformed URL.
+type PermanentError struct {
+	URL        string
+	StatusCode int   // 0 when the request could not be built
+	Err        error // Set when the request could not be built
+}
+
+func (e *PermanentError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("GET %s: %v", e.URL, e.Err)
+	}
+	return fmt.Sprintf("GET %s: permanent failure: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Gone reports whether the page no longer exists (404 or 410).
+func (e *PermanentError) Gone() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
+
+// TransientError reports a failure that persisted through every attempt
+// but may succeed later: transport errors, timeouts, 408, 429 and 5xx.
+type TransientError struct {
+	URL        string
+	StatusCode int // Last status received, 0 for transport errors
+	Attempts   int
+	Err        error
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("GET %s: after %d attempts: %v", e.URL, e.Attempts, e.Err)
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Fetcher performs GET requests for one website.
+type Fetcher struct {
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+	rate        float64       // Requests per second per host
+	burst       int           // Requests allowed back to back
+	slow        time.Duration // Latency at which the host counts as slow
+	userAgent   string
+}
+
+// New builds a Fetcher from the website's Timeout, MaxRetries, RetryDelay,
+// RequestsPerSecond, Burst and UserAgent. A response slower than a third of
+// the timeout counts as slow for adaptive rate limiting.
+func New(cfg config.WebsiteConfig) *Fetcher {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	f := &Fetcher{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     30 * time.Second,
+			},
+		},
+		maxAttempts: max(cfg.MaxRetries, 1),
+		retryDelay:  time.Duration(max(cfg.RetryDelay, 1)) * time.Second,
+		rate:        cfg.RequestsPerSecond,
+		burst:       cfg.Burst,
+		slow:        timeout / 3,
+		userAgent:   userAgent,
+	}
+	f.client.CheckRedirect = f.checkRedirect
+	return f
+}
+
+// maxRedirects is how many redirects a request follows, as in net/http.
+const maxRedirects = 10
+
+// checkRobotsKey marks requests whose redirect targets must be allowed by
+// robots.txt. Requests for robots.txt itself are not marked.
+type checkRobotsKey struct{}
+
+// checkRedirect stops a redirect to a URL that robots.txt disallows, so
+// redirects cannot lead the fetcher where it may not go.
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.Context().Value(checkRobotsKey{}) == nil {
+		return nil
+	}
+	_, err := f.allowed(req.URL)
+	return err
+}
+
+// allowed checks robots.txt for u, returning its rules when the URL may be
+// fetched. Errors are logged by Robots. While robots.txt is unreachable
+// every path is disallowed, which is reported as a *TransientError so the
+// URL is retried rather than skipped; otherwise a disallowed URL yields a
+// *DisallowedError.
+func (f *Fetcher) allowed(u *url.URL) (*Robots, error) {
+	robots, err := f.Robots(u.String())
+	if robots == nil {
+		return nil, nil
+	}
+	if !robots.Allowed(u.RequestURI()) {
+		if err != nil {
+			return nil, &TransientError{URL: u.String(), Attempts: 1,
+				Err: fmt.Errorf("robots.txt unavailable: %w", err)}
+		}
+		return nil, &DisallowedError{URL: u.String()}
+	}
+	return robots, nil
+}
+
+// Get fetches url and returns the response for any 2xx status; the caller
+// must close its body. URLs that robots.txt disallows for our user agent
+// fail with a *DisallowedError without being requested, and so do redirects
+// to them. Every attempt first
+// waits for the host's rate limiter, which never runs faster than the
+// robots.txt Crawl-delay. Transport errors, 408, 429 and 5xx responses are
+// retried up to MaxRetries attempts in total and then reported as a
+// *TransientError. Other statuses fail at once with a *PermanentError.
+func (f *Fetcher) Get(url string) (*http.Response, error) {
+	return f.get(url, Validators{}, true)
+}
+
+// GetIfModified is Get made conditional on the given validators. When the
+// server reports the page unchanged it returns ErrNotModified. With empty
+// validators it behaves exactly like Get.
+func (f *Fetcher) GetIfModified(url string, v Validators) (*http.Response, error) {
+	return f.get(url, v, true)
+}
+
+func (f *Fetcher) get(url string, v Validators, checkRobots bool) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &PermanentError{URL: url, Err: err}
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+	limiter := limiterFor(req.URL.Host, f.rate, f.burst, f.slow)
+
+	if checkRobots {
+		robots, err := f.allowed(req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if robots != nil {
+			limiter.applyCrawlDelay(robots.CrawlDelay)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), checkRobotsKey{}, true))
+	}
+
+	var lastErr error
+	var lastStatus int
+	for attempt := 1; attempt <= f.maxAttempts; attempt++ {
+		wait := f.backoff(attempt)
+
+		limiter.Wait()
+		start := time.Now()
+		resp, err := f.client.Do(req)
+		limiter.Observe(time.Since(start), err != nil || retryable(resp.StatusCode))
+
+		var disallowed *DisallowedError
+		var robotsErr *TransientError
+		switch {
+		case errors.As(err, &disallowed):
+			// A redirect led to a URL robots.txt forbids.
+			return nil, disallowed
+		case errors.As(err, &robotsErr):
+			return nil, robotsErr
+		case err != nil:
+			lastErr = err
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return resp, nil
+		case resp.StatusCode == http.StatusNotModified:
+			drain(resp)
+			return nil, ErrNotModified
+		case retryable(resp.StatusCode):
+			lastStatus = resp.StatusCode
+			lastErr = fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+			if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = d
+			}
+			drain(resp)
+		default:
+			drain(resp)
+			return nil, &PermanentError{URL: url, StatusCode: resp.StatusCode}
+		}
+
+		if attempt < f.maxAttempts {
+			log.Printf("Attempt %d failed for %s: %v. Retrying in %v...",
+				attempt, url, lastErr, wait.Round(time.Millisecond))
+			time.Sleep(wait)
+		}
+	}
+	return nil, &TransientError{URL: url, StatusCode: lastStatus, Attempts: f.maxAttempts, Err: lastErr}
+}
+
+// backoff returns RetryDelay doubled for every previous attempt, with up to
+// ±50% jitter so concurrent workers do not retry in lockstep.
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	delay := f.retryDelay << (attempt - 1)
+	jitter := time.Duration(rand.Int64N(int64(delay)))
+	return delay/2 + jitter
+}
+
+// retryable reports whether a status is worth retrying.
+func retryable(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= 500
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date, capped at maxRetryAfter.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(d, 0), maxRetryAfter), true
+}
+
+// RedirectChain returns the URLs that redirected to resp's final URL, in the
+// order they were requested. It is empty when no redirect was followed.
+func RedirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.Response.Request.URL.String())
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// RedirectStatus returns the status of the first redirect followed to
+// reach resp, such as 301 or 302, or 0 when no redirect was followed.
+func RedirectStatus(resp *http.Response) int {
+	status := 0
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		status = req.Response.StatusCode
+	}
+	return status
+}
+
+// drain discards a bounded amount of the body so the connection can be
+// reused, then closes it.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// IsPermanent reports whether err is, or wraps, a *PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// IsDisallowed reports whether err is, or wraps, a *DisallowedError.
+func IsDisallowed(err error) bool {
+	var disallowed *DisallowedError
+	return errors.As(err, &disallowed)
+}
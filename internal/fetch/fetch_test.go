@@ -0,0 +1,61 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+func TestRedirectChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old-story/":
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		case "/moved/":
+			http.Redirect(w, r, "/renamed/", http.StatusFound)
+		case "/renamed/":
+			http.Redirect(w, r, "/story/", http.StatusMovedPermanently)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		chain  []string
+		status int
+	}{
+		{"no redirect", "/story/", nil, 0},
+		{"redirect to the homepage", "/old-story/", []string{"/old-story/"}, http.StatusMovedPermanently},
+		{"first hop of a chain", "/moved/", []string{"/moved/", "/renamed/"}, http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(config.WebsiteConfig{Timeout: 5, MaxRetries: 1, RequestsPerSecond: 100, Burst: 10})
+			resp, err := f.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var chain []string
+			for _, u := range RedirectChain(resp) {
+				chain = append(chain, u[len(server.URL):])
+			}
+			if !reflect.DeepEqual(chain, tt.chain) {
+				t.Errorf("RedirectChain = %q, want %q", chain, tt.chain)
+			}
+			if got := RedirectStatus(resp); got != tt.status {
+				t.Errorf("RedirectStatus = %d, want %d", got, tt.status)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package fetch provides the HTTP fetcher shared by the sitemap, category and
+// article scrapers. This file implements the per-host token bucket that paces
+// requests. Limiters live in a process-wide registry keyed by host, so every
+// worker and scraper talking to the same site shares one budget, and each
+// limiter slows down on its own when the host gets slow or starts failing.
+package fetch
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Adaptive pacing parameters.
+const (
+	ewmaWeight      = 0.2              // Weight of the newest sample in the moving averages
+	maxErrorRate    = 0.2              // Error rate above which the limiter backs off
+	minRateFactor   = 0.1              // Never slow below this fraction of the configured rate
+	recoverStep     = 0.1              // Fraction of the configured rate regained per adjustment
+	adjustInterval  = 10 * time.Second // Minimum time between two rate changes
+	defaultSlowness = 30 * time.Second // Latency considered slow when no timeout is known
+)
+
+// Limiter is a token bucket whose rate adapts to the health of the host.
+type Limiter struct {
+	mu sync.Mutex
+
+	host       string
+	baseRate   float64 // Configured requests per second
+	rate       float64 // Current requests per second
+	burst      float64
+	tokens     float64
+	last       time.Time
+	slow       time.Duration // Average latency above which the limiter backs off
+	latency    time.Duration // Moving average of response latency
+	errorRate  float64       // Moving average of failed requests
+	lastAdjust time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+)
+
+// limiterFor returns the process-wide limiter for host, creating it with the
+// given settings on first use. Later callers share the existing limiter. A
+// non-positive rate disables pacing.
+func limiterFor(host string, rate float64, burst int, slow time.Duration) *Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if l, ok := limiters[host]; ok {
+		return l
+	}
+	if slow <= 0 {
+		slow = defaultSlowness
+	}
+	l := &Limiter{
+		host:     host,
+		baseRate: rate,
+		rate:     rate,
+		burst:    float64(max(burst, 1)),
+		tokens:   float64(max(burst, 1)),
+		last:     time.Now(),
+		slow:     slow,
+	}
+	limiters[host] = l
+	return l
+}
+
+// Wait blocks until the caller may send a request.
+func (l *Limiter) Wait() {
+	if d := l.reserve(time.Now()); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// reserve takes a token, possibly going into debt, and returns how long the
+// caller must wait for the token to become available.
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+	if now.After(l.last) {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// applyCrawlDelay caps the limiter so requests are at least delay apart.
+func (l *Limiter) applyCrawlDelay(delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ceiling := 1 / delay.Seconds()
+	if l.baseRate <= 0 || l.baseRate > ceiling {
+		log.Printf("Honouring robots.txt Crawl-delay of %v for %s", delay, l.host)
+		l.baseRate = ceiling
+		l.burst = 1
+		l.tokens = min(l.tokens, l.burst)
+	}
+	if l.rate <= 0 || l.rate > ceiling {
+		l.rate = ceiling
+	}
+}
+
+// Observe records the outcome of a request and adapts the rate: it halves
+// when the average latency exceeds the slow threshold or the error rate
+// climbs, and recovers gradually towards the configured rate once the host
+// is healthy again.
+func (l *Limiter) Observe(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failure := 0.0
+	if failed {
+		failure = 1
+	}
+	if l.latency == 0 {
+		l.latency = latency
+	} else {
+		l.latency = time.Duration(ewmaWeight*float64(latency) + (1-ewmaWeight)*float64(l.latency))
+	}
+	l.errorRate = ewmaWeight*failure + (1-ewmaWeight)*l.errorRate
+
+	now := time.Now()
+	if now.Sub(l.lastAdjust) < adjustInterval {
+		return
+	}
+
+	switch {
+	case l.latency > l.slow || l.errorRate > maxErrorRate:
+		if rate := max(l.rate/2, l.baseRate*minRateFactor); rate < l.rate {
+			l.rate = rate
+			l.lastAdjust = now
+			log.Printf("Slowing down requests to %s: %.2f req/s (latency %v, error rate %.0f%%)",
+				l.host, l.rate, l.latency.Round(time.Millisecond), l.errorRate*100)
+		}
+	case l.latency < l.slow/2 && l.errorRate < maxErrorRate/4 && l.rate < l.baseRate:
+		l.rate = min(l.rate+l.baseRate*recoverStep, l.baseRate)
+		l.lastAdjust = now
+		log.Printf("Speeding up requests to %s: %.2f req/s", l.host, l.rate)
+	}
+}
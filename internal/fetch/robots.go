@@ -0,0 +1,247 @@
+// Package fetch provides the HTTP fetcher shared by the sitemap, category and
+// article scrapers. This file downloads, caches and evaluates robots.txt. The
+// cache is process-wide and keyed by scheme and host, so every scraper sees the
+// same rules and robots.txt is fetched once per day per site.
+package fetch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long robots.txt outcomes are cached.
+const (
+	robotsTTL      = 24 * time.Hour
+	robotsErrorTTL = 10 * time.Minute // After the file could not be fetched
+)
+
+// DisallowedError reports a URL that robots.txt forbids us to fetch.
+type DisallowedError struct {
+	URL string
+}
+
+func (e *DisallowedError) Error() string {
+	return fmt.Sprintf("GET %s: disallowed by robots.txt", e.URL)
+}
+
+// Robots holds the robots.txt rules that apply to our user agent.
+type Robots struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration // Crawl-delay for our group, 0 when unset
+	Sitemaps   []string      // Sitemap: URLs listed anywhere in the file
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// Allowed reports whether a path (with query) may be fetched. The longest
+// matching rule wins and Allow wins ties, as in RFC 9309.
+func (r *Robots) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allowed, longest = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// compileRobotsPattern turns a robots.txt path pattern into a regular
+// expression, supporting the * wildcard and the trailing $ end anchor.
+func compileRobotsPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.Compile(expr)
+}
+
+// parseRobots reads robots.txt and keeps the group that best matches agent:
+// the group whose User-agent is the longest substring of agent, or the *
+// group when none matches. Groups naming the same agent are merged.
+func parseRobots(r io.Reader, agent string) (*Robots, error) {
+	agent = strings.ToLower(agent)
+
+	type group struct {
+		agents     []string
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+	var groups []*group
+	var current *group
+	robots := &Robots{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "user-agent":
+			// Consecutive User-agent lines share one group.
+			if current == nil || len(current.rules) > 0 || current.crawlDelay > 0 {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil || value == "" {
+				continue
+			}
+			re, err := compileRobotsPattern(value)
+			if err != nil {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: name == "allow", pattern: value, re: re})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				current.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Pick the most specific matching agent, falling back to "*".
+	best := ""
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a != "*" && strings.Contains(agent, a) && len(a) > len(best) {
+				best = a
+			}
+		}
+	}
+	if best == "" {
+		best = "*"
+	}
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == best {
+				robots.rules = append(robots.rules, g.rules...)
+				robots.CrawlDelay = max(robots.CrawlDelay, g.crawlDelay)
+				break
+			}
+		}
+	}
+	return robots, nil
+}
+
+type robotsEntry struct {
+	mu      sync.Mutex
+	robots  *Robots
+	err     error
+	expires time.Time
+}
+
+var (
+	robotsMu    sync.Mutex
+	robotsCache = make(map[string]*robotsEntry)
+)
+
+// Robots returns the robots.txt rules for the site serving rawURL, fetching
+// and caching them on first use. When robots.txt is missing (4xx) every
+// path is allowed. When the server fails (5xx) or cannot be reached, every
+// path is disallowed as RFC 9309 requires, the error is returned, and the
+// fetch is retried after ten minutes.
+func (f *Fetcher) Robots(rawURL string) (*Robots, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	key := u.Scheme + "://" + u.Host
+
+	robotsMu.Lock()
+	entry, ok := robotsCache[key]
+	if !ok {
+		entry = &robotsEntry{}
+		robotsCache[key] = entry
+	}
+	robotsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.robots != nil && time.Now().Before(entry.expires) {
+		return entry.robots, entry.err
+	}
+
+	entry.robots, entry.err = f.fetchRobots(key + "/robots.txt")
+	ttl := robotsTTL
+	if entry.err != nil {
+		ttl = robotsErrorTTL
+		log.Printf("Could not fetch %s/robots.txt, disallowing all paths for now: %v", key, entry.err)
+	}
+	entry.expires = time.Now().Add(ttl)
+	return entry.robots, entry.err
+}
+
+// fetchRobots downloads and parses robots.txt. A missing file is not an
+// error; a server, network or read failure yields rules that disallow
+// everything.
+func (f *Fetcher) fetchRobots(robotsURL string) (*Robots, error) {
+	resp, err := f.get(robotsURL, Validators{}, false)
+	if IsPermanent(err) {
+		return &Robots{}, nil
+	}
+	if err != nil {
+		return disallowAll(), err
+	}
+	defer resp.Body.Close()
+
+	robots, err := parseRobots(io.LimitReader(resp.Body, 512<<10), robotsAgent(f.userAgent))
+	if err != nil {
+		return disallowAll(), err
+	}
+	return robots, nil
+}
+
+// disallowAll returns rules that forbid every path, used while robots.txt
+// cannot be read.
+func disallowAll() *Robots {
+	re, _ := compileRobotsPattern("/")
+	return &Robots{rules: []robotsRule{{allow: false, pattern: "/", re: re}}}
+}
+
+// robotsAgent returns the product token of a User-Agent header, which is
+// what robots.txt User-agent lines are matched against.
+func robotsAgent(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, "/")
+	token, _, _ = strings.Cut(token, " ")
+	return token
+}
@@ -0,0 +1,167 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		robots  string
+		path    string
+		allowed bool
+	}{
+		{"no rules", "User-agent: *\n", "/news/", true},
+		{"empty disallow", "User-agent: *\nDisallow:\n", "/news/", true},
+		{"disallow prefix", "User-agent: *\nDisallow: /wp-admin/\n", "/wp-admin/options.php", false},
+		{"outside prefix", "User-agent: *\nDisallow: /wp-admin/\n", "/news/", true},
+		{"longest match allows", "User-agent: *\nDisallow: /wp-admin/\nAllow: /wp-admin/admin-ajax.php\n", "/wp-admin/admin-ajax.php", true},
+		{"longest match disallows", "User-agent: *\nAllow: /news/\nDisallow: /news/private/\n", "/news/private/a", false},
+		{"allow wins ties", "User-agent: *\nDisallow: /page\nAllow: /page\n", "/page", true},
+		{"wildcard", "User-agent: *\nDisallow: /*?s=\n", "/search/?s=tinubu", false},
+		{"wildcard miss", "User-agent: *\nDisallow: /*?s=\n", "/news/story/", true},
+		{"end anchor", "User-agent: *\nDisallow: /*.pdf$\n", "/files/report.pdf", false},
+		{"end anchor miss", "User-agent: *\nDisallow: /*.pdf$\n", "/files/report.pdf?download=1", true},
+		{"specific agent wins", "User-agent: *\nDisallow: /\n\nUser-agent: ng-news-scraper\nDisallow: /private/\n", "/news/", true},
+		{"other agent ignored", "User-agent: otherbot\nDisallow: /\n", "/news/", true},
+		{"merged groups", "User-agent: ng-news-scraper\nDisallow: /a/\n\nUser-agent: ng-news-scraper\nDisallow: /b/\n", "/b/x", false},
+		{"comments", "User-agent: * # everyone\nDisallow: /tmp/ # scratch\n", "/tmp/x", false},
+		{"empty path is root", "User-agent: *\nDisallow: /$\n", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			robots, err := parseRobots(strings.NewReader(tt.robots), "ng-news-scraper")
+			if err != nil {
+				t.Fatalf("parseRobots: %v", err)
+			}
+			if got := robots.Allowed(tt.path); got != tt.allowed {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.path, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestRobotsCrawlDelayAndSitemaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		robots   string
+		delay    time.Duration
+		sitemaps int
+	}{
+		{"unset", "User-agent: *\nDisallow: /x\n", 0, 0},
+		{"whole seconds", "User-agent: *\nCrawl-delay: 5\n", 5 * time.Second, 0},
+		{"fractional", "User-agent: *\nCrawl-delay: 0.5\n", 500 * time.Millisecond, 0},
+		{"invalid ignored", "User-agent: *\nCrawl-delay: soon\n", 0, 0},
+		{"other group ignored", "User-agent: otherbot\nCrawl-delay: 30\n\nUser-agent: *\nCrawl-delay: 2\n", 2 * time.Second, 0},
+		{"largest of merged groups", "User-agent: *\nCrawl-delay: 2\n\nUser-agent: *\nCrawl-delay: 4\n", 4 * time.Second, 0},
+		{"sitemaps outside groups", "Sitemap: https://blueprint.ng/sitemap_index.xml\nUser-agent: otherbot\nSitemap: https://blueprint.ng/news-sitemap.xml\n", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			robots, err := parseRobots(strings.NewReader(tt.robots), "ng-news-scraper")
+			if err != nil {
+				t.Fatalf("parseRobots: %v", err)
+			}
+			if robots.CrawlDelay != tt.delay {
+				t.Errorf("CrawlDelay = %v, want %v", robots.CrawlDelay, tt.delay)
+			}
+			if len(robots.Sitemaps) != tt.sitemaps {
+				t.Errorf("Sitemaps = %q, want %d entries", robots.Sitemaps, tt.sitemaps)
+			}
+		})
+	}
+}
+
+func TestDisallowAll(t *testing.T) {
+	robots := disallowAll()
+	for _, path := range []string{"", "/", "/news/story/", "/robots.txt"} {
+		if robots.Allowed(path) {
+			t.Errorf("Allowed(%q) = true, want false", path)
+		}
+	}
+}
+
+func TestRobotsFetchFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		allowed bool
+		wantErr bool
+	}{
+		{"missing file allows all", http.StatusNotFound, true, false},
+		{"forbidden allows all", http.StatusForbidden, true, false},
+		{"server error disallows all", http.StatusServiceUnavailable, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			f := New(config.WebsiteConfig{Timeout: 5, MaxRetries: 1, RequestsPerSecond: 100, Burst: 10})
+			robots, err := f.Robots(server.URL + "/news/")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Robots error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := robots.Allowed("/news/"); got != tt.allowed {
+				t.Errorf("Allowed = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestRobotsRedirects(t *testing.T) {
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case "/redirprivate":
+			http.Redirect(w, r, "/private/x", http.StatusFound)
+		case "/redirpublic":
+			http.Redirect(w, r, "/news/story/", http.StatusMovedPermanently)
+		case "/redirchain":
+			http.Redirect(w, r, "/redirprivate", http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		disallowed bool
+	}{
+		{"redirect to disallowed path", "/redirprivate", true},
+		{"redirect chain to disallowed path", "/redirchain", true},
+		{"redirect to allowed path", "/redirpublic", false},
+		{"disallowed path", "/private/y", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = nil
+			f := New(config.WebsiteConfig{Timeout: 5, MaxRetries: 1, RequestsPerSecond: 100, Burst: 10})
+			resp, err := f.Get(server.URL + tt.path)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if IsDisallowed(err) != tt.disallowed {
+				t.Fatalf("Get error = %v, want disallowed %v", err, tt.disallowed)
+			}
+			for _, path := range fetched {
+				if strings.HasPrefix(path, "/private/") {
+					t.Errorf("fetched disallowed path %s", path)
+				}
+			}
+		})
+	}
+}
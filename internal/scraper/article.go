@@ -5,78 +5,305 @@
 package scraper
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/jerryagenyi/go_ng_news_scraper/internal/config" // Fix import path
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/archive"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/thumbnail"
+	"github.com/lib/pq"
 )
 
-// CSS selectors for Blueprint.ng
-const (
-	titleSelector     = "h1.entry-title"
-	categorySelector  = "div.cat-links a"
-	authorSelector    = "span.author.vcard a"
-	publishedSelector = "time.entry-date.published"
-	updatedSelector   = "time.updated"
-	contentSelector   = "#post-695218 > div > p"
-)
+// ErrExtractionFailed is returned by SaveArticle for articles whose content
+// could not be extracted. Their body is not stored.
+var ErrExtractionFailed = errors.New("extraction failed")
 
 type ArticleScraper struct {
-	db     *sql.DB
-	config config.WebsiteConfig
-	client *http.Client
+	db      *sql.DB
+	config  config.WebsiteConfig
+	fetcher *fetch.Fetcher
+	archive *archive.Store   // nil when ArchiveDir is not configured
+	thumbs  *thumbnail.Store // nil when ThumbnailDir is not configured
 }
 
 func NewArticleScraper(db *sql.DB, config config.WebsiteConfig) *ArticleScraper {
-	return &ArticleScraper{
-		db:     db,
-		config: config,
-		client: &http.Client{},
+	as := &ArticleScraper{
+		db:      db,
+		config:  config,
+		fetcher: fetch.New(config),
 	}
+	if config.ArchiveDir != "" {
+		store, err := archive.Open(config.ArchiveDir)
+		if err != nil {
+			log.Printf("Raw page archive disabled: %v", err)
+		} else {
+			as.archive = store
+		}
+	}
+	if config.ThumbnailDir != "" {
+		store, err := thumbnail.Open(config.ThumbnailDir, config.ThumbnailWidth)
+		if err != nil {
+			log.Printf("Image thumbnails disabled: %v", err)
+		} else {
+			as.thumbs = store
+		}
+	}
+	return as
 }
 
 type Article struct {
 	ID            int // Add this field
 	Title         string
-	Categories    []string // Category names for display
-	Author        string
+	Categories    []string        // Category names for display
+	Author        string          // Author names joined with ", "
+	Authors       []ArticleAuthor // Authors in byline order
 	PublishDate   time.Time
 	UpdatedDate   time.Time
-	Content       string
+	Content       string // Plain text rendered from the same blocks as Markdown
+	Markdown      string // Body with headings, lists, quotes and links kept
 	URL           string
 	CategoryIDs   []int    // Category IDs for database relations
 	CategorySlugs []string // Category slugs for matching
+	CategoryURLs  []string // Category archive URLs, parallel to CategorySlugs
+	Tags          []string // Tag names for display
+	TagSlugs      []string // Tag slugs, parallel to Tags
 	ContentHash   string   // Add this field
+	ETag          string   // Cache validators of the fetched page
+	LastModified  string
+
+	ExtractionFailed bool     // Less than MinContentLength characters of content were found
+	ParseWarnings    []string // Values that could not be parsed, such as unrecognised dates
+
+	// Fields only found in structured metadata, and where each field came from.
+	Section      string // Main section named by the page metadata
+	Keywords     []string
+	ImageURL     string            // Lead image
+	Media        []ArticleMedia    // Featured and inline images, featured first
+	FieldSources map[string]string // SourceJSONLD, SourceMeta, SourceSelector or SourceReadability, by field name
+
+	// Fetch metadata, recorded with the article.
+	StatusCode    int
+	FinalURL      string   // URL the page was served from after redirects
+	RedirectChain []string // URLs that redirected to FinalURL, in order
+	CanonicalURL  string   // rel=canonical link or redirect target; the article is stored under its canonical form
+	ContentType   string
+	FetchLatency  time.Duration // Time to fetch the page, including retries and pacing
 }
 
 func (as *ArticleScraper) ScrapeArticle(url string) (*Article, error) {
+	return as.scrapeArticle(url, fetch.Validators{})
+}
+
+// ScrapeArticleIfModified scrapes an article conditionally on the ETag and
+// Last-Modified stored with it. It returns fetch.ErrNotModified when the
+// server reports the page unchanged; MarkUnchanged then records the visit.
+func (as *ArticleScraper) ScrapeArticleIfModified(url string) (*Article, error) {
+	var etag, lastModified sql.NullString
+	err := as.db.QueryRow(`
+		SELECT etag, last_modified FROM go_articles
+		WHERE url = `+storedArticleURL+`
+	`, as.config.ID, url).Scan(&etag, &lastModified)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to load article validators: %w", err)
+	}
+	return as.scrapeArticle(url, fetch.Validators{ETag: etag.String, LastModified: lastModified.String})
+}
+
+// MarkUnchanged records a visit to an article that was not modified, so
+// incremental runs skip it until the sitemap reports a newer lastmod.
+func (as *ArticleScraper) MarkUnchanged(url string) error {
+	if _, err := as.db.Exec(`
+		UPDATE go_articles SET last_scraped_at = NOW()
+		WHERE url = `+storedArticleURL+`
+	`, as.config.ID, url); err != nil {
+		return fmt.Errorf("failed to update last scraped time: %w", err)
+	}
+	log.Printf("Article not modified: %s", url)
+	return nil
+}
+
+func (as *ArticleScraper) scrapeArticle(url string, validators fetch.Validators) (*Article, error) {
 	log.Printf("Scraping article: %s", url)
 
-	resp, err := as.client.Get(url)
+	start := time.Now()
+	resp, err := as.fetcher.GetIfModified(url, validators)
 	if err != nil {
+		var permanent *fetch.PermanentError
+		if errors.As(err, &permanent) && permanent.Gone() {
+			as.markInvalid(url, permanent.StatusCode)
+		}
 		return nil, fmt.Errorf("failed to fetch article: %w", err)
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read article: %w", err)
+	}
+	latency := time.Since(start)
+
+	// Sites often answer removed articles with a redirect to the homepage
+	// rather than a 404. The redirect's status is recorded, not the
+	// homepage's 200, so the URL does not look successfully fetched.
+	chain := fetch.RedirectChain(resp)
+	finalURL := resp.Request.URL.String()
+	if path := resp.Request.URL.Path; len(chain) > 0 && (path == "" || path == "/") {
+		status := fetch.RedirectStatus(resp)
+		as.markInvalid(url, status)
+		return nil, &fetch.PermanentError{URL: url, StatusCode: status,
+			Err: fmt.Errorf("redirected (%d) to the homepage %s", status, finalURL)}
+	}
+
+	if as.archive != nil {
+		as.archivePage(url, resp, body)
+	}
+
+	article, err := as.extractArticle(url, body, start)
+	if err != nil {
+		return nil, err
+	}
+	v := fetch.ValidatorsOf(resp)
+	article.ETag, article.LastModified = v.ETag, v.LastModified
+	article.StatusCode = resp.StatusCode
+	article.FinalURL = finalURL
+	article.RedirectChain = chain
+	article.ContentType = resp.Header.Get("Content-Type")
+	article.FetchLatency = latency
+	if len(chain) > 0 && finalURL != url {
+		log.Printf("Article %s redirected to %s", url, finalURL)
+		if article.CanonicalURL == "" && sameSite(finalURL, as.config.BaseURL) {
+			article.CanonicalURL = finalURL
+		}
+	}
+	return article, nil
+}
+
+// markInvalid flags a sitemap URL whose article is gone so it is no longer
+// treated as a live article. Failures are logged.
+func (as *ArticleScraper) markInvalid(url string, statusCode int) {
+	_, err := as.db.Exec(`
+		UPDATE go_sitemaps
+		SET is_valid = false, status_code = $3, last_checked = NOW()
+		WHERE website_id = $1 AND article_url = $2
+	`, as.config.ID, url, statusCode)
+	if err != nil {
+		log.Printf("Error marking %s invalid: %v", url, err)
+		return
+	}
+	log.Printf("Marked %s invalid (status %d)", url, statusCode)
+}
+
+// archivePage stores a fetched article body and indexes it in go_raw_pages.
+// Failures are logged; archiving never fails a scrape.
+func (as *ArticleScraper) archivePage(url string, resp *http.Response, body []byte) {
+	hash, err := as.archive.Put(body)
+	if err != nil {
+		log.Printf("Error archiving %s: %v", url, err)
+		return
+	}
+	v := fetch.ValidatorsOf(resp)
+	_, err = as.db.Exec(`
+		INSERT INTO go_raw_pages (website_id, url, sha256, status_code, content_type, etag, last_modified)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+		ON CONFLICT (website_id, url, sha256) DO UPDATE SET
+			status_code = EXCLUDED.status_code,
+			content_type = EXCLUDED.content_type,
+			etag = EXCLUDED.etag,
+			last_modified = EXCLUDED.last_modified,
+			fetched_at = NOW()
+	`, as.config.ID, url, hash, resp.StatusCode, resp.Header.Get("Content-Type"),
+		v.ETag, v.LastModified)
+	if err != nil {
+		log.Printf("Error indexing archived page %s: %v", url, err)
+	}
+}
+
+// extractArticle applies the website's selectors to an article page.
+// Relative dates such as "2 hours ago" are resolved against fetchedAt.
+func (as *ArticleScraper) extractArticle(url string, body []byte, fetchedAt time.Time) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	article := &Article{URL: url}
+	if href, exists := doc.Find(`link[rel="canonical"]`).Attr("href"); exists {
+		article.CanonicalURL = canonicalLink(href, url, as.config.BaseURL)
+	}
 
-	// Extract title
-	article.Title = strings.TrimSpace(doc.Find(titleSelector).Text())
+	selectors := as.config.Selectors
+
+	// Structured metadata wins over the theme-dependent CSS selectors.
+	jsonLD, metaTags, selected := readJSONLD(doc), readMetaTags(doc), readSelectors(doc, selectors)
+	sources := []*pageMetadata{jsonLD, metaTags, selected}
+	article.FieldSources = make(map[string]string)
+	pick := func(field string, get func(*pageMetadata) string) string {
+		value, source := firstField(sources, get)
+		if source != "" {
+			article.FieldSources[field] = source
+		}
+		return value
+	}
+	// pickDate takes the first value that parses, recording a warning for
+	// every unrecognised one it passes over.
+	pickDate := func(field string, get func(*pageMetadata) string) time.Time {
+		for _, meta := range sources {
+			t, warning := parseDate(field, get(meta), fetchedAt)
+			if warning != "" {
+				article.ParseWarnings = append(article.ParseWarnings, warning+" ("+meta.source+")")
+			}
+			if t != nil {
+				article.FieldSources[field] = meta.source
+				return *t
+			}
+		}
+		return time.Time{}
+	}
+
+	// Except for the title: og:title is often the share title, so the page's
+	// own heading is preferred to it.
+	title, source := firstField([]*pageMetadata{jsonLD, selected, metaTags}, func(m *pageMetadata) string { return m.title })
+	if source != "" {
+		article.Title = title
+		article.FieldSources["title"] = source
+	}
+	for _, meta := range sources {
+		if authors := normaliseAuthors(meta.authors, url); len(authors) > 0 {
+			article.Authors = authors
+			article.Author = authorNames(authors)
+			article.FieldSources["author"] = meta.source
+			break
+		}
+	}
+	article.Section = pick("section", func(m *pageMetadata) string { return m.section })
+	article.ImageURL = pick("image", func(m *pageMetadata) string { return m.image })
+	article.PublishDate = pickDate("published", func(m *pageMetadata) string { return m.published })
+	article.UpdatedDate = pickDate("updated", func(m *pageMetadata) string { return m.updated })
+	for _, warning := range article.ParseWarnings {
+		log.Printf("Warning: %s in %s", warning, url)
+	}
+	for _, meta := range sources {
+		if len(meta.keywords) > 0 {
+			article.Keywords = meta.keywords
+			article.FieldSources["keywords"] = meta.source
+			break
+		}
+	}
 
 	// Extract categories
-	doc.Find(categorySelector).Each(func(i int, s *goquery.Selection) {
+	findFirst(doc, selectors.Categories).Each(func(i int, s *goquery.Selection) {
 		categoryName := strings.TrimSpace(s.Text())
 		if href, exists := s.Attr("href"); exists {
 			// Extract slug from category URL
@@ -87,50 +314,107 @@ func (as *ArticleScraper) ScrapeArticle(url string) (*Article, error) {
 				slug := strings.TrimSuffix(parts[1], "/")
 				article.CategorySlugs = append(article.CategorySlugs, slug)
 				article.Categories = append(article.Categories, categoryName)
+				if link := canonicalLink(href, url, as.config.BaseURL); link != "" {
+					href = link
+				}
+				article.CategoryURLs = append(article.CategoryURLs, href)
 				log.Printf("Found category: %s (slug: %s)", categoryName, slug)
 			}
 		}
 	})
 
-	// Extract author
-	article.Author = strings.TrimSpace(doc.Find(authorSelector).Text())
+	if len(article.CategorySlugs) > 0 {
+		article.FieldSources["categories"] = SourceSelector
+	}
+
+	// Extract tags. Links outside /tag/ archives are keyed by their name.
+	seenTags := make(map[string]bool)
+	findFirst(doc, selectors.Tags).Each(func(i int, s *goquery.Selection) {
+		name := strings.TrimSpace(s.Text())
+		href, _ := s.Attr("href")
+		slug, ok := tagSlug(href)
+		if !ok {
+			slug = slugify(name)
+		}
+		if name == "" || slug == "" || seenTags[slug] {
+			return
+		}
+		seenTags[slug] = true
+		article.Tags = append(article.Tags, name)
+		article.TagSlugs = append(article.TagSlugs, slug)
+	})
+	if len(article.TagSlugs) > 0 {
+		article.FieldSources["tags"] = SourceSelector
+	}
 
-	// Extract dates
-	if dateStr, exists := doc.Find(publishedSelector).Attr("datetime"); exists {
-		if publishDate, err := time.Parse(time.RFC3339, dateStr); err == nil {
-			article.PublishDate = publishDate
+	// Extract content. Selectors naming the body's paragraphs render their
+	// whole container, so headings, lists and quotes between them are kept.
+	// Paragraphs inside quotes or share boxes have containers of their own
+	// within the body, so only the outermost containers are rendered.
+	var contentRoot *goquery.Selection
+	for _, selector := range selectors.Content {
+		containers := doc.Find(selector)
+		if containers.Length() > 0 && containers.Filter(blockContent).Length() == containers.Length() {
+			containers = containers.Parent()
+		}
+		containers = outermost(containers)
+		if markdown, content := renderContent(containers, url); content != "" {
+			article.Markdown, article.Content = markdown, content
+			article.FieldSources["content"] = SourceSelector
+			contentRoot = containers
+			break
 		}
 	}
 
-	if dateStr, exists := doc.Find(updatedSelector).Attr("datetime"); exists {
-		if updatedDate, err := time.Parse(time.RFC3339, dateStr); err == nil {
-			article.UpdatedDate = updatedDate
+	// Fall back to scoring the page when the selectors found too little, and
+	// flag the article rather than store a blank body if that fails too.
+	if len(article.Content) < as.config.MinContentLength {
+		if block := readableBlock(doc); block != nil {
+			if markdown, content := renderContent(block, url); len(content) > len(article.Content) {
+				log.Printf("Content selectors found %d characters, using readability fallback (%d) for %s",
+					len(article.Content), len(content), url)
+				article.Markdown, article.Content = markdown, content
+				article.FieldSources["content"] = SourceReadability
+				contentRoot = block
+			}
 		}
 	}
+	article.ExtractionFailed = len(article.Content) < as.config.MinContentLength
 
-	// Extract content
-	var contentBuilder strings.Builder
-	doc.Find(contentSelector).Each(func(i int, s *goquery.Selection) {
-		contentBuilder.WriteString(strings.TrimSpace(s.Text()))
-		contentBuilder.WriteString("\n\n")
-	})
-	article.Content = strings.TrimSpace(contentBuilder.String())
+	var inline []ArticleMedia
+	if contentRoot != nil {
+		inline = bodyImages(contentRoot)
+	}
+	article.Media = mergeMedia(url, jsonLD.images, metaTags.images, inline)
 
 	log.Printf("Found article: %s with %d categories", article.Title, len(article.Categories))
 	return article, nil
 }
 
+// findFirst returns the matches of the first selector in the list that
+// matches at least one element. An empty selection is returned when none match.
+func findFirst(doc *goquery.Document, selectors []string) *goquery.Selection {
+	for _, selector := range selectors {
+		if sel := doc.Find(selector); sel.Length() > 0 {
+			return sel
+		}
+	}
+	return doc.Selection.Slice(0, 0)
+}
+
 func (as *ArticleScraper) hasChanged(existing, new *Article) bool {
 	if existing.Title != new.Title ||
 		existing.Author != new.Author ||
 		existing.ContentHash != new.ContentHash || // Compare hashes instead of content
-		!sameCategories(existing.CategorySlugs, new.CategorySlugs) {
+		!sameSlugs(existing.CategorySlugs, new.CategorySlugs) ||
+		!sameSlugs(existing.TagSlugs, new.TagSlugs) {
 
 		log.Printf("Changes detected in article: %s", new.Title)
 		log.Printf("- Title changed: %v", existing.Title != new.Title)
 		log.Printf("- Content changed: %v", existing.ContentHash != new.ContentHash)
 		log.Printf("- Author changed: %v", existing.Author != new.Author)
-		log.Printf("- Categories changed: %v", !sameCategories(existing.CategorySlugs, new.CategorySlugs))
+		log.Printf("- Categories changed: %v", !sameSlugs(existing.CategorySlugs, new.CategorySlugs))
+		log.Printf("- Tags changed: %v", !sameSlugs(existing.TagSlugs, new.TagSlugs))
 		return true
 	}
 
@@ -138,7 +422,7 @@ func (as *ArticleScraper) hasChanged(existing, new *Article) bool {
 	return false
 }
 
-func sameCategories(a, b []string) bool {
+func sameSlugs(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
 	}
@@ -161,19 +445,67 @@ func (as *ArticleScraper) SaveArticle(article *Article) error {
 	}
 	defer tx.Rollback()
 
-	// Calculate hash before saving
-	article.ContentHash = CalculateContentHash(article.Content)
+	// Calculate hash before saving. The Markdown is hashed so that changed
+	// links or structure count as a change too.
+	article.ContentHash = CalculateContentHash(article.Markdown)
 
-	// Get existing article if any
+	// Store the article under its canonical URL, so variants of the same
+	// story share one row.
+	storedURL := article.URL
+	if article.CanonicalURL != "" {
+		storedURL = article.CanonicalURL
+	}
+	storedURL = canonicalURL(storedURL, as.config)
+	article.CanonicalURL = storedURL
+	if storedURL != article.URL {
+		if err := as.recordCanonical(tx, article.URL, storedURL); err != nil {
+			return err
+		}
+	}
+
+	if article.ExtractionFailed {
+		// Record the failure even on a first scrape, but keep whatever body
+		// an earlier scrape stored.
+		if _, err := tx.Exec(`
+			INSERT INTO go_articles (
+				website_id,
+				title,
+				content,
+				content_markdown,
+				url,
+				created_at,
+				last_scraped_at,
+				extraction_failed
+			) VALUES ($1, $2, '', '', $3, NOW(), NOW(), true)
+			ON CONFLICT (url) DO UPDATE SET
+				extraction_failed = true,
+				last_scraped_at = NOW()
+		`, as.config.ID, article.Title, storedURL); err != nil {
+			return fmt.Errorf("failed to flag extraction failure: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %d characters of content in %s", ErrExtractionFailed, len(article.Content), article.URL)
+	}
+
+	// Get existing article if any. Rows recorded by a failed extraction
+	// have no author or content hash.
 	var existing Article
+	var title, content, author, contentHash sql.NullString
 	err = tx.QueryRow(`
 		SELECT id, title, content, author, content_hash
 		FROM go_articles 
 		WHERE url = $1
-	`, article.URL).Scan(&existing.ID, &existing.Title, &existing.Content,
-		&existing.Author, &existing.ContentHash)
+	`, storedURL).Scan(&existing.ID, &title, &content, &author, &contentHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to load existing article: %w", err)
+	}
 
 	if err == nil {
+		existing.Title, existing.Content = title.String, content.String
+		existing.Author, existing.ContentHash = author.String, contentHash.String
+
 		// Get existing categories
 		rows, err := tx.Query(`
 			SELECT c.slug 
@@ -190,10 +522,52 @@ func (as *ArticleScraper) SaveArticle(article *Article) error {
 			}
 		}
 
+		// Get existing tags
+		tagRows, err := tx.Query(`
+			SELECT t.slug
+			FROM go_tags t
+			JOIN go_article_tags at ON t.id = at.tag_id
+			WHERE at.article_id = $1
+		`, existing.ID)
+		if err == nil {
+			for tagRows.Next() {
+				var slug string
+				tagRows.Scan(&slug)
+				existing.TagSlugs = append(existing.TagSlugs, slug)
+			}
+			tagRows.Close()
+		}
+
 		// Check if anything meaningful has changed
 		if !as.hasChanged(&existing, article) {
 			log.Printf("No meaningful changes detected for: %s", article.Title)
-			return nil
+			// Still record the visit so incremental runs skip it until the
+			// sitemap reports a newer lastmod.
+			if _, err := tx.Exec(`
+				UPDATE go_articles
+				SET last_scraped_at = NOW(), extraction_failed = false,
+					etag = NULLIF($2, ''), last_modified = NULLIF($3, ''),
+					publish_date = COALESCE(publish_date, $4),
+					last_updated = COALESCE(last_updated, $5)
+				WHERE id = $1
+			`, existing.ID, article.ETag, article.LastModified,
+				nullTime(article.PublishDate), nullTime(article.UpdatedDate)); err != nil {
+				return fmt.Errorf("failed to update last scraped time: %w", err)
+			}
+			if err := recordFetch(tx, existing.ID, article); err != nil {
+				return err
+			}
+			if err := recordMetadata(tx, existing.ID, article); err != nil {
+				return err
+			}
+			if err := recordMedia(tx, existing.ID, article.Media); err != nil {
+				return err
+			}
+			if err := recordAuthors(tx, as.config.ID, existing.ID, article.Authors); err != nil {
+				return err
+			}
+			article.ID = existing.ID
+			return tx.Commit()
 		}
 		log.Printf("Changes detected, updating article: %s", article.Title)
 	}
@@ -210,23 +584,49 @@ func (as *ArticleScraper) SaveArticle(article *Article) error {
 			publish_date,
 			last_updated,
 			url,
-			created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+			created_at,
+			last_scraped_at,
+			etag,
+			last_modified,
+			content_markdown
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), NULLIF($9, ''), NULLIF($10, ''), $11)
 		ON CONFLICT (url) DO UPDATE SET
 			title = $2,
 			content = $3,
+			content_markdown = $11,
 			content_hash = $4,  -- Add this
 			author = $5,
-			publish_date = $6,
-			last_updated = $7
+			publish_date = COALESCE($6, go_articles.publish_date),
+			last_updated = COALESCE($7, go_articles.last_updated),
+			last_scraped_at = NOW(),
+			extraction_failed = false,
+			etag = EXCLUDED.etag,
+			last_modified = EXCLUDED.last_modified
 		RETURNING id
 	`, as.config.ID, article.Title, article.Content, article.ContentHash,
-		article.Author, article.PublishDate, article.UpdatedDate,
-		article.URL).Scan(&articleID)
+		article.Author, nullTime(article.PublishDate), nullTime(article.UpdatedDate),
+		storedURL, article.ETag, article.LastModified, article.Markdown).Scan(&articleID)
 
 	if err != nil {
 		return fmt.Errorf("failed to upsert article: %w", err)
 	}
+	if err := recordFetch(tx, articleID, article); err != nil {
+		return err
+	}
+	if err := recordMetadata(tx, articleID, article); err != nil {
+		return err
+	}
+	if err := recordMedia(tx, articleID, article.Media); err != nil {
+		return err
+	}
+	if err := recordAuthors(tx, as.config.ID, articleID, article.Authors); err != nil {
+		return err
+	}
+	article.ID = articleID
+
+	if err := recordTags(tx, as.config.ID, articleID, article); err != nil {
+		return err
+	}
 
 	// Only update categories if the article was changed or is new
 	// First, delete existing category relationships
@@ -246,11 +646,11 @@ func (as *ArticleScraper) SaveArticle(article *Article) error {
 			SELECT id FROM go_categories 
 			WHERE website_id = $1 AND slug = $2
 		`, as.config.ID, slug).Scan(&categoryID)
-
+		if err == sql.ErrNoRows {
+			categoryID, err = as.createCategory(tx, article, i)
+		}
 		if err != nil {
-			log.Printf("Category not found for slug '%s' (display name: '%s')",
-				slug, article.Categories[i])
-			continue
+			return err
 		}
 
 		_, err = tx.Exec(`
@@ -267,6 +667,144 @@ func (as *ArticleScraper) SaveArticle(article *Article) error {
 	return tx.Commit()
 }
 
+// storedArticleURL is an SQL expression for the go_articles URL under which
+// the article at sitemap URL $2 of website $1 is stored.
+const storedArticleURL = `COALESCE((
+			SELECT canonical_url FROM go_sitemaps
+			WHERE website_id = $1 AND article_url = $2
+		), $2)`
+
+// recordCanonical remembers in go_sitemaps that the article listed at
+// sitemapURL is stored under canonical, so incremental runs find it.
+func (as *ArticleScraper) recordCanonical(tx *sql.Tx, sitemapURL, canonical string) error {
+	_, err := tx.Exec(`
+		UPDATE go_sitemaps SET canonical_url = $3
+		WHERE website_id = $1 AND article_url = $2
+	`, as.config.ID, sitemapURL, canonical)
+	if err != nil {
+		return fmt.Errorf("failed to record canonical URL: %w", err)
+	}
+	return nil
+}
+
+// recordFetch stores the HTTP metadata of the fetch that produced article.
+// Articles rebuilt from the archive carry no fetch metadata, in which case
+// the values of the original fetch are kept.
+func recordFetch(tx *sql.Tx, articleID int, article *Article) error {
+	_, err := tx.Exec(`
+		UPDATE go_articles SET
+			status_code = COALESCE(NULLIF($2, 0), status_code),
+			final_url = COALESCE(NULLIF($3, ''), final_url),
+			redirect_chain = CASE WHEN $3 = '' THEN redirect_chain ELSE $4::text[] END,
+			canonical_url = COALESCE(NULLIF($5, ''), canonical_url, url),
+			content_type = COALESCE(NULLIF($6, ''), content_type),
+			fetch_latency_ms = COALESCE(NULLIF($7, 0), fetch_latency_ms)
+		WHERE id = $1
+	`, articleID, article.StatusCode, article.FinalURL, pq.Array(article.RedirectChain),
+		article.CanonicalURL, article.ContentType, article.FetchLatency.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to record fetch metadata: %w", err)
+	}
+	return nil
+}
+
+// recordMetadata stores the fields taken from structured metadata and the
+// source of every extracted field.
+func recordMetadata(tx *sql.Tx, articleID int, article *Article) error {
+	sources, err := json.Marshal(article.FieldSources)
+	if err != nil {
+		return fmt.Errorf("failed to encode field sources: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE go_articles SET
+			section = NULLIF($2, ''),
+			keywords = $3,
+			image_url = NULLIF($4, ''),
+			field_sources = $5,
+			parse_warnings = $6
+		WHERE id = $1
+	`, articleID, article.Section, pq.Array(article.Keywords), article.ImageURL, string(sources),
+		pq.Array(article.ParseWarnings))
+	if err != nil {
+		return fmt.Errorf("failed to record article metadata: %w", err)
+	}
+	return nil
+}
+
+// createCategory stores the article's i-th category, which the category
+// sitemap has not listed yet, so the article keeps the link. The category is
+// flagged discovered_from_article until a category import confirms it.
+func (as *ArticleScraper) createCategory(tx *sql.Tx, article *Article, i int) (int, error) {
+	slug := article.CategorySlugs[i]
+	parentID := 0
+	if as.config.CategoryStructure != config.CategoryFlat {
+		var err error
+		parentID, err = findParentCategoryID(tx, as.config.ID, slug)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find parent of category %s: %w", slug, err)
+		}
+	}
+
+	// Another worker may create the same category concurrently.
+	var categoryID int
+	err := tx.QueryRow(`
+		INSERT INTO go_categories (
+			website_id, name, slug, url, parent_id, created_at, discovered_from_article
+		) VALUES ($1, $2, $3, $4, $5, NOW(), true)
+		ON CONFLICT (website_id, slug) DO UPDATE SET slug = EXCLUDED.slug
+		RETURNING id
+	`, as.config.ID, article.Categories[i], slug, article.CategoryURLs[i],
+		sql.NullInt32{Int32: int32(parentID), Valid: parentID > 0}).Scan(&categoryID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create category %s: %w", slug, err)
+	}
+	log.Printf("Created category %s (slug: %s) found on %s", article.Categories[i], slug, article.URL)
+	return categoryID, nil
+}
+
+// nullTime stores the zero time as NULL rather than 0001-01-01.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// recordMedia replaces the article's images with media. Rows for images
+// that are still present are updated in place, keeping their thumbnails.
+func recordMedia(tx *sql.Tx, articleID int, media []ArticleMedia) error {
+	urls := make([]string, 0, len(media))
+	for i, m := range media {
+		_, err := tx.Exec(`
+			INSERT INTO go_article_media (
+				article_id, url, position, featured, alt, caption, credit, width, height, source
+			) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''),
+				NULLIF($8, 0), NULLIF($9, 0), $10)
+			ON CONFLICT (article_id, url) DO UPDATE SET
+				position = EXCLUDED.position,
+				featured = EXCLUDED.featured,
+				alt = EXCLUDED.alt,
+				caption = EXCLUDED.caption,
+				credit = EXCLUDED.credit,
+				width = EXCLUDED.width,
+				height = EXCLUDED.height,
+				source = EXCLUDED.source,
+				updated_at = NOW()
+		`, articleID, m.URL, i, m.Featured, m.Alt, m.Caption, m.Credit, m.Width, m.Height, m.Source)
+		if err != nil {
+			return fmt.Errorf("failed to save image %s: %w", m.URL, err)
+		}
+		urls = append(urls, m.URL)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM go_article_media WHERE article_id = $1 AND url <> ALL($2)
+	`, articleID, pq.Array(urls)); err != nil {
+		return fmt.Errorf("failed to remove stale images: %w", err)
+	}
+	return nil
+}
+
 func CalculateContentHash(content string) string {
 	hasher := sha256.New()
 	hasher.Write([]byte(content))
@@ -0,0 +1,55 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+const contentPage = `<html><body><article>
+<h1 class="entry-title">Senate passes budget</h1>
+<div class="entry-content">
+	<p>The Senate passed the budget on Tuesday.</p>
+	<h2>Reactions</h2>
+	<blockquote>
+		<p>This is a good day.</p>
+		<blockquote><p>Quoted from the floor.</p></blockquote>
+	</blockquote>
+	<p>The bill now goes to the President.</p>
+	<div class="sharedaddy"><p>Share this:</p><p><a href="/share/fb">Facebook</a> <a href="/share/tw">Twitter</a></p></div>
+</div>
+<div class="share-box"><p>Share this: Facebook Twitter</p></div>
+</article></body></html>`
+
+func TestExtractArticleContent(t *testing.T) {
+	const want = "The Senate passed the budget on Tuesday.\n\n" +
+		"## Reactions\n\n" +
+		"> This is a good day.\n>\n> > Quoted from the floor.\n\n" +
+		"The bill now goes to the President."
+
+	tests := []struct {
+		name     string
+		selector string
+	}{
+		{"container", "div.entry-content"},
+		{"child paragraphs", "div.entry-content > p"},
+		{"descendant paragraphs", "article div.entry-content p"},
+		{"paragraphs outside the body", "article p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &ArticleScraper{config: config.WebsiteConfig{
+				BaseURL:   "https://blueprint.ng",
+				Selectors: config.Selectors{Content: []string{tt.selector}},
+			}}
+			article, err := as.extractArticle("https://blueprint.ng/budget/", []byte(contentPage), time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if article.Markdown != want {
+				t.Errorf("Markdown =\n%s\nwant\n%s", article.Markdown, want)
+			}
+		})
+	}
+}
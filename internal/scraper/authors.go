@@ -0,0 +1,224 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file turns bylines into individual authors: "By X and Y" strings and
+// co-bylines are split, "By" prefixes, datelines and news agency credits are
+// stripped, and each author is stored once per website in go_authors with its
+// profile URL, linked to articles through go_article_authors.
+package scraper
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ArticleAuthor is one author credited on an article.
+type ArticleAuthor struct {
+	Name       string
+	ProfileURL string // Author page on the site, when the byline links to one
+}
+
+var (
+	// bylinePrefix matches the words that introduce a byline.
+	bylinePrefix = regexp.MustCompile(`(?i)^(?:(?:written|reported|compiled|posted|edited)\s+)?by\s*:?\s+`)
+	// bylineSeparator splits co-bylines. "&" is handled by splitAmpersand.
+	bylineSeparator = regexp.MustCompile(`(?i)\s*(?:,|;|\||\band\b)\s*`)
+	// ampersand joins co-bylines, but also firms such as "Alexander & Co".
+	ampersand = regexp.MustCompile(`\s*&\s*`)
+	// agencyReport matches trailing "with agency reports" credits.
+	agencyReport = regexp.MustCompile(`(?i)\s*[,(]?\s*(?:with|and)\s+(?:additional\s+)?agency\s+reports?\)?\s*$`)
+	// bracketed matches a parenthesised agency or dateline, as in "John Doe (NAN)".
+	bracketed = regexp.MustCompile(`\s*\([^)]*\)`)
+)
+
+// agencies are news agency credits stripped from bylines. A byline made up
+// only of agencies keeps them as its authors.
+var agencies = map[string]bool{
+	"nan": true, "news agency of nigeria": true, "reuters": true, "afp": true,
+	"agence france-presse": true, "ap": true, "associated press": true,
+	"xinhua": true, "bloomberg": true, "anadolu": true, "agency": true,
+	"agencies": true, "agency report": true, "agency reports": true,
+}
+
+// organisationWords mark a side of an "&" as part of a firm's name rather
+// than a person's, as in "Alexander & Co" or "Okafor & Sons".
+var organisationWords = map[string]bool{
+	"co": true, "company": true, "sons": true, "partners": true, "associates": true,
+	"ltd": true, "limited": true, "plc": true, "inc": true, "llp": true, "group": true,
+}
+
+// datelines are places that follow the author in bylines such as
+// "John Doe, Abuja" and are not names.
+var datelines = map[string]bool{
+	"abuja": true, "lagos": true, "kano": true, "port harcourt": true, "ibadan": true,
+	"enugu": true, "kaduna": true, "jos": true, "benin": true, "benin city": true,
+	"owerri": true, "calabar": true, "uyo": true, "maiduguri": true, "ilorin": true,
+	"abeokuta": true, "akure": true, "asaba": true, "sokoto": true, "yola": true,
+	"makurdi": true, "lokoja": true, "minna": true, "bauchi": true, "gombe": true,
+	"osogbo": true, "ado-ekiti": true, "awka": true, "umuahia": true, "abakaliki": true,
+	"yenagoa": true, "dutse": true, "damaturu": true, "jalingo": true, "lafia": true,
+	"katsina": true, "birnin kebbi": true, "gusau": true, "london": true, "washington": true,
+}
+
+// splitByline returns the author names in a byline.
+func splitByline(byline string) []string {
+	byline = strings.Join(strings.Fields(byline), " ")
+	byline = bylinePrefix.ReplaceAllString(byline, "")
+	byline = agencyReport.ReplaceAllString(byline, "")
+
+	var parts []string
+	for _, part := range bylineSeparator.Split(byline, -1) {
+		parts = append(parts, splitAmpersand(part)...)
+	}
+
+	var names, agencyNames []string
+	for _, part := range parts {
+		// "John Doe (NAN)" credits the agency; keep it in case nobody
+		// else is named.
+		if m := bracketed.FindString(part); m != "" {
+			inner := strings.Trim(strings.TrimSpace(m), "()")
+			if agencies[strings.ToLower(inner)] {
+				agencyNames = append(agencyNames, inner)
+			}
+			part = bracketed.ReplaceAllString(part, "")
+		}
+		part = strings.Trim(strings.TrimSpace(part), ".-–—:")
+		key := strings.ToLower(part)
+		switch {
+		case part == "" || datelines[key]:
+		case agencies[key]:
+			agencyNames = append(agencyNames, part)
+		default:
+			names = append(names, part)
+		}
+	}
+	if len(names) == 0 {
+		return agencyNames
+	}
+	return names
+}
+
+// splitAmpersand splits "X & Y" only when every side is a personal name or
+// a news agency, so firms such as "Alexander & Co" or "Ernst & Young" stay
+// whole.
+func splitAmpersand(part string) []string {
+	sides := ampersand.Split(part, -1)
+	if len(sides) < 2 {
+		return sides
+	}
+	for _, side := range sides {
+		side = strings.TrimSpace(bracketed.ReplaceAllString(side, ""))
+		if !personalName(side) && !agencies[strings.ToLower(side)] {
+			return []string{part}
+		}
+	}
+	return sides
+}
+
+// personalName reports whether s looks like a person's name: at least two
+// capitalised words, none of which belongs to a firm's name.
+func personalName(s string) bool {
+	words := strings.Fields(s)
+	if len(words) < 2 {
+		return false
+	}
+	for _, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		if !unicode.IsUpper(r) || organisationWords[strings.ToLower(strings.Trim(word, ".,"))] {
+			return false
+		}
+	}
+	return true
+}
+
+// normaliseAuthors splits the bylines of one source into individual
+// authors. A profile URL is only kept when its byline names one author.
+func normaliseAuthors(bylines []ArticleAuthor, pageURL string) []ArticleAuthor {
+	base, _ := url.Parse(pageURL)
+	var out []ArticleAuthor
+	seen := make(map[string]bool)
+	for _, byline := range bylines {
+		names := splitByline(byline.Name)
+		for _, name := range names {
+			slug := slugify(name)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			author := ArticleAuthor{Name: name}
+			if len(names) == 1 && byline.ProfileURL != "" && base != nil {
+				if ref, err := url.Parse(byline.ProfileURL); err == nil {
+					author.ProfileURL = base.ResolveReference(ref).String()
+				}
+			}
+			out = append(out, author)
+		}
+	}
+	return out
+}
+
+// slugify is the key an author or tag is stored under: the lower-cased
+// name with runs of other characters turned into hyphens.
+func slugify(name string) string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return slug.String()
+}
+
+// authorNames joins the authors' names for the go_articles.author column.
+func authorNames(authors []ArticleAuthor) string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// recordAuthors upserts the article's authors into go_authors and replaces
+// its go_article_authors links, keeping the byline order.
+func recordAuthors(tx *sql.Tx, websiteID, articleID int, authors []ArticleAuthor) error {
+	if _, err := tx.Exec(`
+		DELETE FROM go_article_authors WHERE article_id = $1
+	`, articleID); err != nil {
+		return fmt.Errorf("failed to clear existing authors: %w", err)
+	}
+
+	for i, author := range authors {
+		var authorID int
+		err := tx.QueryRow(`
+			INSERT INTO go_authors (website_id, name, slug, profile_url)
+			VALUES ($1, $2, $3, NULLIF($4, ''))
+			ON CONFLICT (website_id, slug) DO UPDATE SET
+				name = EXCLUDED.name,
+				profile_url = COALESCE(EXCLUDED.profile_url, go_authors.profile_url),
+				updated_at = NOW()
+			RETURNING id
+		`, websiteID, author.Name, slugify(author.Name), author.ProfileURL).Scan(&authorID)
+		if err != nil {
+			return fmt.Errorf("failed to save author %s: %w", author.Name, err)
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO go_article_authors (article_id, author_id, position)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, articleID, authorID, i); err != nil {
+			return fmt.Errorf("failed to link author %s: %w", author.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitByline(t *testing.T) {
+	tests := []struct {
+		byline string
+		want   []string
+	}{
+		{"Ade Bello", []string{"Ade Bello"}},
+		{"By Ade Bello", []string{"Ade Bello"}},
+		{"Written by: Ade Bello", []string{"Ade Bello"}},
+		{"By Ade Bello and Chika Okafor", []string{"Ade Bello", "Chika Okafor"}},
+		{"Ade Bello, Chika Okafor; Musa Ibrahim", []string{"Ade Bello", "Chika Okafor", "Musa Ibrahim"}},
+		{"Ade Bello & Chika Okafor", []string{"Ade Bello", "Chika Okafor"}},
+		{"Ade Bello (NAN) & Chika Okafor", []string{"Ade Bello", "Chika Okafor"}},
+		{"Alexander & Co", []string{"Alexander & Co"}},
+		{"Alexander Okafor & Co", []string{"Alexander Okafor & Co"}},
+		{"Okafor & Sons Ltd", []string{"Okafor & Sons Ltd"}},
+		{"Ernst & Young", []string{"Ernst & Young"}},
+		{"By Ade Bello and Alexander & Co", []string{"Ade Bello", "Alexander & Co"}},
+		{"Reuters & AFP", []string{"Reuters", "AFP"}},
+		{"Ade Bello, Abuja", []string{"Ade Bello"}},
+		{"Ade Bello (NAN)", []string{"Ade Bello"}},
+		{"NAN", []string{"NAN"}},
+		{"By Ade Bello, with agency reports", []string{"Ade Bello"}},
+		{"Ade Bello | Reuters", []string{"Ade Bello"}},
+		{"  By   Ade   Bello  ", []string{"Ade Bello"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.byline, func(t *testing.T) {
+			if got := splitByline(tt.byline); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitByline(%q) = %q, want %q", tt.byline, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormaliseAuthors(t *testing.T) {
+	const page = "https://blueprint.ng/story/"
+	tests := []struct {
+		name    string
+		bylines []ArticleAuthor
+		want    []ArticleAuthor
+	}{
+		{
+			"profile of a single author resolved",
+			[]ArticleAuthor{{Name: "By Ade Bello", ProfileURL: "/author/ade-bello/"}},
+			[]ArticleAuthor{{Name: "Ade Bello", ProfileURL: "https://blueprint.ng/author/ade-bello/"}},
+		},
+		{
+			"profile dropped for a shared byline",
+			[]ArticleAuthor{{Name: "Ade Bello and Chika Okafor", ProfileURL: "/author/ade-bello/"}},
+			[]ArticleAuthor{{Name: "Ade Bello"}, {Name: "Chika Okafor"}},
+		},
+		{
+			"co-bylines deduplicated by slug",
+			[]ArticleAuthor{
+				{Name: "Ade Bello", ProfileURL: "https://blueprint.ng/author/ade-bello/"},
+				{Name: "ADE BELLO"},
+				{Name: "Chika Okafor", ProfileURL: "https://blueprint.ng/author/chika/"},
+			},
+			[]ArticleAuthor{
+				{Name: "Ade Bello", ProfileURL: "https://blueprint.ng/author/ade-bello/"},
+				{Name: "Chika Okafor", ProfileURL: "https://blueprint.ng/author/chika/"},
+			},
+		},
+		{
+			"firm kept whole",
+			[]ArticleAuthor{{Name: "By Alexander & Co", ProfileURL: "/author/alexander-co/"}},
+			[]ArticleAuthor{{Name: "Alexander & Co", ProfileURL: "https://blueprint.ng/author/alexander-co/"}},
+		},
+		{
+			"punctuation only",
+			[]ArticleAuthor{{Name: "By -"}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normaliseAuthors(tt.bylines, page); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normaliseAuthors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file normalises article URLs so the variants a story is reached through
+// (tracking parameters, http or https, www or AMP hosts, a missing trailing
+// slash, AMP pages) all map to the single key that go_sitemaps.canonical_url
+// and go_articles.url share.
+package scraper
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+// trackingParams are query parameters that never change the page served.
+// Parameters starting with utm_ are dropped as well.
+var trackingParams = map[string]bool{
+	"amp":        true,
+	"fbclid":     true,
+	"gclid":      true,
+	"dclid":      true,
+	"msclkid":    true,
+	"igshid":     true,
+	"mc_cid":     true,
+	"mc_eid":     true,
+	"_ga":        true,
+	"ref":        true,
+	"outputtype": true, // outputType=amp
+}
+
+// canonicalURL returns the canonical form of an article URL, the key that
+// variants of one story share in go_sitemaps.canonical_url and go_articles.
+// It is never fetched: sitemap URLs are requested exactly as listed.
+//
+// Every URL loses its fragment, user info and default port, and its scheme
+// and host are lower-cased. URLs on the website's own host (ignoring www.
+// and amp. prefixes) also take the scheme and host of BaseURL, lose tracking
+// parameters, have the remaining query sorted and a trailing /amp segment
+// removed. With TrailingSlash set their paths are given the trailing slash
+// WordPress permalinks use, unless the last segment has a file extension.
+// URLs on other hosts keep their path and query. URLs that cannot be parsed
+// are returned trimmed.
+func canonicalURL(raw string, site config.WebsiteConfig) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	base, err := url.Parse(site.BaseURL)
+	if err != nil || base.Host == "" || siteHost(u.Hostname()) != siteHost(base.Hostname()) {
+		return u.String()
+	}
+	u.Scheme = strings.ToLower(base.Scheme)
+	u.Host = strings.ToLower(base.Host)
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode() // Encode sorts by key
+
+	p := strings.TrimSuffix(u.Path, "/")
+	p = strings.TrimSuffix(p, "/amp")
+	switch {
+	case p == "":
+		p = "/"
+	case site.TrailingSlash && !strings.Contains(path.Base(p), "."):
+		p += "/"
+	case strings.HasSuffix(u.Path, "/"):
+		p += "/" // Keep the slash the URL was listed with
+	}
+	u.Path = p
+	u.RawPath = ""
+
+	return u.String()
+}
+
+// canonicalLink resolves a <link rel="canonical"> href found on pageURL. It
+// returns "" for links that point off-site or at the homepage, which themes
+// emit on error pages and which must not replace the article's own URL.
+func canonicalLink(href, pageURL, baseURL string) string {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	link, err := page.Parse(strings.TrimSpace(href))
+	if err != nil || link.Host == "" {
+		return ""
+	}
+	if !sameSite(link.String(), baseURL) || strings.Trim(link.Path, "/") == "" {
+		return ""
+	}
+	return link.String()
+}
+
+// siteHost strips the www. and amp. prefixes that serve the same site.
+func siteHost(host string) string {
+	host = strings.ToLower(host)
+	for _, prefix := range []string{"www.", "amp."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
+
+// sameSite reports whether rawURL is on the website's own host.
+func sameSite(rawURL, baseURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	return siteHost(u.Hostname()) == siteHost(base.Hostname())
+}
@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	slashed := config.WebsiteConfig{BaseURL: "https://blueprint.ng", TrailingSlash: true}
+	plain := config.WebsiteConfig{BaseURL: "https://blueprint.ng"}
+
+	tests := []struct {
+		name string
+		site config.WebsiteConfig
+		raw  string
+		want string
+	}{
+		// Tracking parameters
+		{"utm params", slashed, "https://blueprint.ng/story/?utm_source=fb&utm_medium=social", "https://blueprint.ng/story/"},
+		{"click ids", slashed, "https://blueprint.ng/story/?fbclid=abc&gclid=def", "https://blueprint.ng/story/"},
+		{"ref and amp params", slashed, "https://blueprint.ng/story/?ref=home&amp=1", "https://blueprint.ng/story/"},
+		{"other params kept and sorted", slashed, "https://blueprint.ng/?p=12&b=2&utm_campaign=x", "https://blueprint.ng/?b=2&p=12"},
+		{"fragment", slashed, "https://blueprint.ng/story/#comments", "https://blueprint.ng/story/"},
+
+		// AMP variants
+		{"amp segment", slashed, "https://blueprint.ng/story/amp/", "https://blueprint.ng/story/"},
+		{"amp segment without slash", slashed, "https://blueprint.ng/story/amp", "https://blueprint.ng/story/"},
+		{"amp host", slashed, "https://amp.blueprint.ng/story/amp/", "https://blueprint.ng/story/"},
+
+		// Scheme and host of the site
+		{"http", slashed, "http://blueprint.ng/story/", "https://blueprint.ng/story/"},
+		{"www", slashed, "https://www.blueprint.ng/story/", "https://blueprint.ng/story/"},
+		{"upper case host", slashed, "HTTPS://BluePrint.NG/story/", "https://blueprint.ng/story/"},
+		{"default port", slashed, "https://blueprint.ng:443/story/", "https://blueprint.ng/story/"},
+
+		// Trailing slash
+		{"slash added", slashed, "https://blueprint.ng/story", "https://blueprint.ng/story/"},
+		{"file keeps no slash", slashed, "https://blueprint.ng/feed.xml", "https://blueprint.ng/feed.xml"},
+		{"root", slashed, "https://blueprint.ng", "https://blueprint.ng/"},
+		{"slash not added without option", plain, "https://blueprint.ng/story", "https://blueprint.ng/story"},
+		{"listed slash kept without option", plain, "https://blueprint.ng/story/", "https://blueprint.ng/story/"},
+		{"amp without option keeps listed slash", plain, "https://blueprint.ng/story/amp/", "https://blueprint.ng/story/"},
+		{"amp without option or slash", plain, "https://blueprint.ng/story/amp", "https://blueprint.ng/story"},
+
+		// Other hosts
+		{"other host path untouched", slashed, "https://other.com/x", "https://other.com/x"},
+		{"other host query untouched", slashed, "https://other.com/x?ref=1&utm_source=a", "https://other.com/x?ref=1&utm_source=a"},
+		{"other host amp untouched", slashed, "https://other.com/x/amp", "https://other.com/x/amp"},
+		{"other host scheme kept", slashed, "HTTP://Other.com/x#top", "http://other.com/x"},
+		{"subdomain is another host", slashed, "https://sports.blueprint.ng/x", "https://sports.blueprint.ng/x"},
+
+		// Unparseable and relative values
+		{"relative", slashed, " /story/ ", "/story/"},
+		{"invalid", slashed, "http://[::1", "http://[::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalURL(tt.raw, tt.site); got != tt.want {
+				t.Errorf("canonicalURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
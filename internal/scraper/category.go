@@ -1,87 +1,90 @@
 // Package scraper implements the core scraping functionality for Nigerian news websites.
 // This file specifically handles the extraction and storage of category information
 // from news websites' category sitemaps, maintaining hierarchical relationships
-// between categories where applicable.
+// between categories where applicable. Categories first seen on an article page
+// are created by SaveArticle and flagged discovered_from_article until the
+// category sitemap lists them.
 package scraper
 
 import (
 	"database/sql"
-	"encoding/xml"
+	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"sort"
 	"strings"
-	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
 )
 
-type CategorySitemap struct {
-	XMLName xml.Name `xml:"urlset"`
-	URLs    []struct {
-		Loc     string `xml:"loc"`
-		LastMod string `xml:"lastmod,omitempty"`
-	} `xml:"url"`
-}
-
 type CategoryScraper struct {
-	db     *sql.DB
-	config config.WebsiteConfig
-	client *http.Client
+	db      *sql.DB
+	config  config.WebsiteConfig
+	fetcher *fetch.Fetcher
 }
 
 func NewCategoryScraper(db *sql.DB, config config.WebsiteConfig) *CategoryScraper {
 	return &CategoryScraper{
-		db:     db,
-		config: config,
-		client: &http.Client{
-			Timeout: time.Duration(config.Timeout) * time.Second,
-		},
+		db:      db,
+		config:  config,
+		fetcher: fetch.New(config),
 	}
 }
 
+// categoryEntry is one category listed in the category sitemap.
+type categoryEntry struct {
+	id   int
+	slug string
+	url  string
+}
+
+// ScrapeCategories imports the category sitemap in two passes: every
+// category is stored first, then parents are resolved, so a child listed
+// before its parent is still linked. In flat mode every category is left
+// without a parent. In hierarchical mode nested slugs such as
+// "news/politics" give the parent, and with category_breadcrumbs the
+// breadcrumb trail of each remaining category page is read as well.
 func (cs *CategoryScraper) ScrapeCategories() error {
 	log.Printf("Starting category scraping for %s", cs.config.Name)
-	log.Printf("Fetching categories from: %s", cs.config.CategorySitemapURL)
 
-	resp, err := cs.client.Get(cs.config.CategorySitemapURL)
+	sitemapURL, err := cs.categorySitemapURL()
 	if err != nil {
-		return fmt.Errorf("failed to fetch category sitemap: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	log.Printf("Fetching categories from: %s", sitemapURL)
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := cs.fetcher.Get(sitemapURL)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to fetch category sitemap: %w", err)
 	}
+	defer resp.Body.Close()
 
-	var sitemap CategorySitemap
-	if err := xml.Unmarshal(body, &sitemap); err != nil {
-		return fmt.Errorf("failed to parse XML: %w", err)
+	sitemap, err := newSitemapReader(resp.Body, cs.config.MaxSitemapBytes, cs.config.MaxSitemapURLs)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Found %d categories in sitemap", len(sitemap.URLs))
-
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 	stmt, err := tx.Prepare(`
     INSERT INTO go_categories (
         website_id, 
         name, 
         slug, 
         url, 
-        parent_id,
         created_at
     )
-    VALUES ($1, $2, $3, $4, $5, NOW())
+    VALUES ($1, $2, $3, $4, NOW())
     ON CONFLICT (website_id, slug) 
     DO UPDATE SET 
         name = $2, 
         url = $4,
-        parent_id = $5
+        discovered_from_article = false
     RETURNING id
 `)
 	if err != nil {
@@ -89,29 +92,44 @@ func (cs *CategoryScraper) ScrapeCategories() error {
 	}
 	defer stmt.Close()
 
-	for _, url := range sitemap.URLs {
+	// First pass: store every category.
+	var entries []categoryEntry
+	for url, err := range sitemap.URLs() {
+		if err != nil {
+			return fmt.Errorf("failed to read category sitemap: %w", err)
+		}
+
 		// Extract category name and slug from URL
 		name, slug := extractCategoryInfo(url.Loc)
 		log.Printf("Processing category: %s (slug: %s)", name, slug)
 
-		parentID, err := cs.findParentID(tx, slug)
-		if err != nil {
-			log.Printf("Error finding parent for %s: %v", slug, err)
-			continue
+		entry := categoryEntry{slug: slug, url: url.Loc}
+		// A failed insert aborts the transaction, so stop rather than carry
+		// on with statements that would all fail.
+		if err := stmt.QueryRow(cs.config.ID, name, slug, url.Loc).Scan(&entry.id); err != nil {
+			return fmt.Errorf("failed to insert category %s: %w", url.Loc, err)
 		}
+		entries = append(entries, entry)
+	}
 
-		var id int
-		err = stmt.QueryRow(
-			cs.config.ID,
-			name,
-			slug,
-			url.Loc,
-			sql.NullInt32{Int32: int32(parentID), Valid: parentID > 0},
-		).Scan(&id)
-
-		if err != nil {
-			log.Printf("Error inserting category %s: %v", url.Loc, err)
-			continue
+	// Second pass: resolve parents now that every category exists.
+	var unresolved []categoryEntry
+	for _, entry := range entries {
+		parentID := 0
+		if cs.config.CategoryStructure != config.CategoryFlat {
+			parentID, err = cs.findParentID(tx, entry.slug)
+			if err != nil {
+				return fmt.Errorf("failed to find parent of %s: %w", entry.slug, err)
+			}
+			// Top-level slugs keep their parent until the breadcrumbs say
+			// otherwise, so a failed page fetch does not lose it.
+			if parentID == 0 && !strings.Contains(entry.slug, "/") && cs.config.CategoryBreadcrumbs {
+				unresolved = append(unresolved, entry)
+				continue
+			}
+		}
+		if err := setCategoryParent(tx, entry.id, parentID); err != nil {
+			return err
 		}
 	}
 
@@ -119,35 +137,70 @@ func (cs *CategoryScraper) ScrapeCategories() error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Successfully processed categories for %s", cs.config.Name)
+	if len(unresolved) > 0 {
+		cs.resolveFromBreadcrumbs(unresolved)
+	}
+
+	log.Printf("Successfully processed %d categories for %s", len(entries), cs.config.Name)
 	return nil
 }
 
+// setCategoryParent links a category to its parent, or clears the link
+// when parentID is 0. db is the import transaction or the database itself.
+func setCategoryParent(db interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}, categoryID, parentID int) error {
+	_, err := db.Exec(`
+        UPDATE go_categories SET parent_id = $2 WHERE id = $1
+    `, categoryID, sql.NullInt32{Int32: int32(parentID), Valid: parentID > 0})
+	if err != nil {
+		return fmt.Errorf("failed to set category parent: %w", err)
+	}
+	return nil
+}
+
+// categorySitemapURL returns the configured category sitemap, or the first
+// category sitemap found by sitemap discovery when none is configured.
+func (cs *CategoryScraper) categorySitemapURL() (string, error) {
+	if cs.config.CategorySitemapURL != "" {
+		return cs.config.CategorySitemapURL, nil
+	}
+
+	discovered, err := NewSitemapScraper(cs.db, cs.config).Discover()
+	if err != nil {
+		return "", err
+	}
+	for _, sitemap := range discovered {
+		if sitemap.Kind == SitemapCategory {
+			return sitemap.URL, nil
+		}
+	}
+	return "", fmt.Errorf("no category sitemap configured or discovered for %s", cs.config.Name)
+}
+
 // extractCategoryInfo parses category information from the URL
 // Example URL: https://blueprint.ng/category/world-stage/
 func extractCategoryInfo(url string) (name, slug string) {
-	// Remove trailing slash if present
-	if url[len(url)-1] == '/' {
-		url = url[:len(url)-1]
-	}
-
-	// Split by "category/"
-	parts := strings.Split(url, "category/")
-	if len(parts) != 2 {
+	slug, ok := categorySlug(url)
+	if !ok {
 		return "Unknown", "unknown"
 	}
 
-	// Get the category slug
-	slug = parts[1]
-
-	// Convert slug to readable name
-	name = strings.Title(strings.ReplaceAll(slug, "-", " "))
+	// Convert the slug's last segment to a readable name, as for tags
+	name = slugName(slug[strings.LastIndex(slug, "/")+1:])
 
 	return name, slug
 }
 
 // Add new method to handle parent-child relationships
 func (cs *CategoryScraper) findParentID(tx *sql.Tx, currentSlug string) (int, error) {
+	return findParentCategoryID(tx, cs.config.ID, currentSlug)
+}
+
+// findParentCategoryID returns the ID of the parent of a nested category
+// slug such as "news/politics", or 0 when it has none or the parent is not
+// stored yet.
+func findParentCategoryID(tx *sql.Tx, websiteID int, currentSlug string) (int, error) {
 	// If slug contains '/', it has a parent
 	parts := strings.Split(currentSlug, "/")
 	if len(parts) == 1 {
@@ -160,10 +213,159 @@ func (cs *CategoryScraper) findParentID(tx *sql.Tx, currentSlug string) (int, er
 	err := tx.QueryRow(`
         SELECT id FROM go_categories 
         WHERE website_id = $1 AND slug = $2
-    `, cs.config.ID, parentSlug).Scan(&parentID)
+    `, websiteID, parentSlug).Scan(&parentID)
 
 	if err == sql.ErrNoRows {
 		return 0, nil
 	}
 	return parentID, err
 }
+
+// resolveFromBreadcrumbs sets the parents of categories whose URLs are not
+// nested from the breadcrumb trail on their category pages. Pages that
+// cannot be fetched or show no breadcrumbs leave the category unchanged.
+func (cs *CategoryScraper) resolveFromBreadcrumbs(entries []categoryEntry) {
+	log.Printf("Reading breadcrumbs of %d category pages", len(entries))
+	for _, entry := range entries {
+		parentSlug, found, err := cs.breadcrumbParent(entry)
+		if err != nil {
+			log.Printf("Error reading breadcrumbs of %s: %v", entry.url, err)
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		var parentID int
+		if parentSlug != "" {
+			err := cs.db.QueryRow(`
+                SELECT id FROM go_categories WHERE website_id = $1 AND slug = $2
+            `, cs.config.ID, parentSlug).Scan(&parentID)
+			if err == sql.ErrNoRows {
+				log.Printf("Breadcrumb parent %s of %s is not a known category", parentSlug, entry.slug)
+				continue
+			}
+			if err != nil {
+				log.Printf("Error finding category %s: %v", parentSlug, err)
+				continue
+			}
+		}
+		if err := setCategoryParent(cs.db, entry.id, parentID); err != nil {
+			log.Printf("Error setting parent of %s: %v", entry.slug, err)
+		}
+	}
+}
+
+// breadcrumbSelectors find breadcrumb links in the themes and SEO plugins
+// common on WordPress news sites, when the website configures none.
+var breadcrumbSelectors = []string{
+	".breadcrumb a", ".breadcrumbs a", "#breadcrumbs a", ".yoast-breadcrumbs a",
+	".rank-math-breadcrumb a", `nav[aria-label="breadcrumb"] a`, `nav[aria-label="Breadcrumb"] a`,
+}
+
+// breadcrumbTypes are the schema.org types of a breadcrumb trail.
+var breadcrumbTypes = map[string]bool{"BreadcrumbList": true}
+
+// breadcrumbParent reads the category page's breadcrumb trail, from
+// JSON-LD or failing that the page markup, and returns the slug of the
+// category just above entry. found is false when the page has no trail; an
+// empty slug with found set means entry is a top-level category.
+func (cs *CategoryScraper) breadcrumbParent(entry categoryEntry) (slug string, found bool, err error) {
+	resp, err := cs.fetcher.Get(entry.url)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to parse category page: %w", err)
+	}
+
+	trail := jsonLDBreadcrumbs(doc)
+	if len(trail) == 0 {
+		selectors := cs.config.Selectors.Breadcrumbs
+		if len(selectors) == 0 {
+			selectors = breadcrumbSelectors
+		}
+		findFirst(doc, selectors).Each(func(i int, s *goquery.Selection) {
+			if href, ok := s.Attr("href"); ok {
+				trail = append(trail, href)
+			}
+		})
+	}
+	if len(trail) == 0 {
+		return "", false, nil
+	}
+
+	// The trail runs from the home page down; the parent is the last
+	// category before this one.
+	for _, link := range trail {
+		crumb, ok := categorySlug(link)
+		if !ok {
+			continue
+		}
+		if crumb == entry.slug {
+			break
+		}
+		slug = crumb
+	}
+	return slug, true, nil
+}
+
+// jsonLDBreadcrumbs returns the item URLs of the page's schema.org
+// BreadcrumbList in position order.
+func jsonLDBreadcrumbs(doc *goquery.Document) []string {
+	var list map[string]any
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return true
+		}
+		list = findJSONNode(data, breadcrumbTypes)
+		return list == nil
+	})
+	if list == nil {
+		return nil
+	}
+
+	items, _ := list["itemListElement"].([]any)
+	type crumb struct {
+		position int
+		url      string
+	}
+	var crumbs []crumb
+	for _, item := range items {
+		element, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		u := jsonString(element["item"])
+		if target, ok := element["item"].(map[string]any); ok {
+			if u = jsonString(target["@id"]); u == "" {
+				u = jsonString(target["url"])
+			}
+		}
+		if u != "" {
+			crumbs = append(crumbs, crumb{jsonInt(element["position"]), u})
+		}
+	}
+	sort.SliceStable(crumbs, func(i, j int) bool { return crumbs[i].position < crumbs[j].position })
+
+	urls := make([]string, len(crumbs))
+	for i, c := range crumbs {
+		urls[i] = c.url
+	}
+	return urls
+}
+
+// categorySlug extracts the slug of a category archive URL, which may be
+// nested: https://blueprint.ng/category/news/politics/ gives "news/politics".
+func categorySlug(url string) (string, bool) {
+	_, slug, ok := strings.Cut(url, "/category/")
+	if !ok {
+		return "", false
+	}
+	slug, _, _ = strings.Cut(slug, "?")
+	slug = strings.Trim(slug, "/")
+	return slug, slug != ""
+}
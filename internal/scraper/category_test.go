@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+)
+
+func TestExtractCategoryInfo(t *testing.T) {
+	tests := []struct {
+		url  string
+		name string
+		slug string
+	}{
+		{"https://blueprint.ng/category/world-stage/", "World Stage", "world-stage"},
+		{"https://blueprint.ng/category/world-stage", "World Stage", "world-stage"},
+		{"https://blueprint.ng/category/news/politics/", "Politics", "news/politics"},
+		{"https://blueprint.ng/category/ìròyìn/?page=2", "Ìròyìn", "ìròyìn"},
+		{"https://blueprint.ng/news/", "Unknown", "unknown"},
+		{"https://blueprint.ng/category/", "Unknown", "unknown"},
+		{"", "Unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			name, slug := extractCategoryInfo(tt.url)
+			if name != tt.name || slug != tt.slug {
+				t.Errorf("extractCategoryInfo(%q) = %q, %q, want %q, %q", tt.url, name, slug, tt.name, tt.slug)
+			}
+		})
+	}
+}
+
+func TestBreadcrumbParent(t *testing.T) {
+	pages := map[string]string{
+		// JSON-LD trail, listed out of position order
+		"/category/elections/": `<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+			{"@type": "WebPage"},
+			{"@type": "BreadcrumbList", "itemListElement": [
+				{"@type": "ListItem", "position": 3, "item": {"@id": "https://blueprint.ng/category/elections/", "name": "Elections"}},
+				{"@type": "ListItem", "position": 1, "item": "https://blueprint.ng/", "name": "Home"},
+				{"@type": "ListItem", "position": 2, "item": {"url": "https://blueprint.ng/category/politics/", "name": "Politics"}}
+			]}
+		]}</script>`,
+		// Theme markup found by the default selectors
+		"/category/senate/": `<div class="breadcrumbs"><a href="/">Home</a> » <a href="/category/politics/">Politics</a> » <a href="/category/elections/">Elections</a> » <a href="/category/senate/">Senate</a></div>`,
+		// A top-level category
+		"/category/politics/": `<nav aria-label="breadcrumb"><a href="/">Home</a> » <a href="/category/politics/">Politics</a></nav>`,
+		// No trail at all
+		"/category/sports/": `<h1>Sports</h1>`,
+		// Markup only the configured selector finds
+		"/category/football/": `<p class="crumbs"><a href="/">Home</a> <a href="/category/sports/">Sports</a> <span>Football</span></p>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><head></head><body>" + page + "</body></html>"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		slug      string
+		selectors []string
+		parent    string
+		found     bool
+	}{
+		{"json-ld", "elections", nil, "politics", true},
+		{"markup", "senate", nil, "elections", true},
+		{"top level", "politics", nil, "", true},
+		{"no trail", "sports", nil, "", false},
+		{"configured selector", "football", []string{"p.crumbs a"}, "sports", true},
+		{"default selectors miss custom markup", "football", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.WebsiteConfig{
+				BaseURL:             server.URL,
+				CategoryBreadcrumbs: true,
+				Timeout:             5,
+				MaxRetries:          1,
+				RequestsPerSecond:   100,
+				Burst:               10,
+				Selectors:           config.Selectors{Breadcrumbs: tt.selectors},
+			}
+			cs := &CategoryScraper{config: cfg, fetcher: fetch.New(cfg)}
+			entry := categoryEntry{slug: tt.slug, url: server.URL + "/category/" + tt.slug + "/"}
+
+			parent, found, err := cs.breadcrumbParent(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parent != tt.parent || found != tt.found {
+				t.Errorf("breadcrumbParent(%s) = %q, %v, want %q, %v", tt.slug, parent, found, tt.parent, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file merges rows stored under non-canonical URLs before the URL
+// canonicaliser existed: duplicate go_articles rows are folded into one,
+// together with their links, and go_sitemaps rows are given the canonical URL
+// that keys their article.
+package scraper
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/lib/pq"
+)
+
+// articleLinks lists the tables that reference go_articles.id, with the
+// column that is unique per article. Rows of merged duplicates are moved to
+// the surviving article unless it already has a row with the same value.
+var articleLinks = []struct {
+	table  string
+	column string
+}{
+	{"go_article_categories", "category_id"},
+	{"go_article_media", "url"},
+	{"go_article_authors", "author_id"},
+	{"go_article_tags", "tag_id"},
+}
+
+// DedupeStats summarises a Deduper run.
+type DedupeStats struct {
+	ArticlesMerged  int // Duplicate article rows folded into another row
+	ArticlesRenamed int // Surviving rows whose URL was rewritten
+	SitemapKeyed    int // go_sitemaps rows given their canonical URL
+}
+
+type Deduper struct {
+	db     *sql.DB
+	config config.WebsiteConfig
+}
+
+func NewDeduper(db *sql.DB, config config.WebsiteConfig) *Deduper {
+	return &Deduper{
+		db:     db,
+		config: config,
+	}
+}
+
+type articleRow struct {
+	id          int
+	url         string
+	canonical   string // canonical_url, or url when none is stored
+	key         string // canonicalURL of canonical, set by groupDuplicates
+	lastScraped time.Time
+}
+
+// Run merges the website's duplicate articles and records the canonical URL
+// of its sitemap rows. With dryRun it only reports what it would change.
+func (d *Deduper) Run(dryRun bool) (DedupeStats, error) {
+	var stats DedupeStats
+	if err := d.dedupeArticles(dryRun, &stats); err != nil {
+		return stats, err
+	}
+	if err := d.keySitemaps(dryRun, &stats); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+func (d *Deduper) dedupeArticles(dryRun bool, stats *DedupeStats) error {
+	rows, err := d.db.Query(`
+        SELECT id, url, COALESCE(canonical_url, url), COALESCE(last_scraped_at, last_updated, created_at)
+        FROM go_articles
+        WHERE website_id = $1
+        ORDER BY id
+    `, d.config.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load articles: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []articleRow
+	for rows.Next() {
+		var row articleRow
+		var lastScraped sql.NullTime
+		if err := rows.Scan(&row.id, &row.url, &row.canonical, &lastScraped); err != nil {
+			return err
+		}
+		row.lastScraped = lastScraped.Time
+		articles = append(articles, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, group := range groupDuplicates(articles, d.config) {
+		survivor := pickSurvivor(group)
+		key := survivor.key
+		var duplicates []int64
+		for _, row := range group {
+			if row.id != survivor.id {
+				duplicates = append(duplicates, int64(row.id))
+			}
+		}
+		if len(duplicates) == 0 && survivor.url == key {
+			continue
+		}
+
+		log.Printf("Merging %d duplicates into article %d (%s)", len(duplicates), survivor.id, key)
+		stats.ArticlesMerged += len(duplicates)
+		if survivor.url != key {
+			stats.ArticlesRenamed++
+		}
+		if dryRun {
+			continue
+		}
+		if err := d.mergeArticles(survivor.id, duplicates, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupDuplicates groups the articles that are the same story. Rows are
+// linked by their canonical key and by the canonical form of their stored
+// URL, so a row whose URL is another row's key joins that row's group and
+// renaming the survivor cannot collide with a row left outside it. Groups
+// are returned in order of their first row.
+func groupDuplicates(articles []articleRow, site config.WebsiteConfig) [][]articleRow {
+	parent := make(map[string]string)
+	find := func(k string) string {
+		if _, ok := parent[k]; !ok {
+			parent[k] = k
+		}
+		for parent[k] != k {
+			parent[k] = parent[parent[k]]
+			k = parent[k]
+		}
+		return k
+	}
+
+	for i := range articles {
+		articles[i].key = canonicalURL(articles[i].canonical, site)
+		a, b := find(articles[i].key), find(canonicalURL(articles[i].url, site))
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	index := make(map[string]int)
+	var groups [][]articleRow
+	for _, row := range articles {
+		root := find(row.key)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], row)
+	}
+	return groups
+}
+
+// pickSurvivor prefers a row already stored under its canonical URL, then
+// the most recently scraped one.
+func pickSurvivor(group []articleRow) articleRow {
+	best := group[0]
+	for _, row := range group[1:] {
+		switch {
+		case best.url == best.key:
+		case row.url == row.key || row.lastScraped.After(best.lastScraped):
+			best = row
+		}
+	}
+	return best
+}
+
+func (d *Deduper) mergeArticles(survivorID int, duplicates []int64, key string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(duplicates) > 0 {
+		for _, link := range articleLinks {
+			// One duplicate at a time, so two duplicates sharing a value
+			// cannot both be moved onto the survivor.
+			for _, duplicate := range duplicates {
+				_, err := tx.Exec(fmt.Sprintf(`
+                    UPDATE %[1]s l SET article_id = $1
+                    WHERE l.article_id = $2
+                      AND NOT EXISTS (
+                          SELECT 1 FROM %[1]s s WHERE s.article_id = $1 AND s.%[2]s = l.%[2]s
+                      )
+                `, link.table, link.column), survivorID, duplicate)
+				if err != nil {
+					return fmt.Errorf("failed to move %s links: %w", link.table, err)
+				}
+			}
+			if _, err := tx.Exec(fmt.Sprintf(`
+                DELETE FROM %s WHERE article_id = ANY($1)
+            `, link.table), pq.Array(duplicates)); err != nil {
+				return fmt.Errorf("failed to delete %s links: %w", link.table, err)
+			}
+		}
+		if _, err := tx.Exec(`
+            DELETE FROM go_articles WHERE id = ANY($1)
+        `, pq.Array(duplicates)); err != nil {
+			return fmt.Errorf("failed to delete duplicate articles: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE go_articles SET url = $2, canonical_url = $2 WHERE id = $1
+    `, survivorID, key); err != nil {
+		return fmt.Errorf("failed to rename article: %w", err)
+	}
+	return tx.Commit()
+}
+
+// keySitemaps records the canonical URL of go_sitemaps rows stored before it
+// was kept alongside the listed URL. The listed URL is left alone, since it
+// is the one that gets fetched.
+func (d *Deduper) keySitemaps(dryRun bool, stats *DedupeStats) error {
+	rows, err := d.db.Query(`
+        SELECT article_url FROM go_sitemaps
+        WHERE website_id = $1 AND canonical_url IS NULL
+    `, d.config.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load sitemap URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return err
+		}
+		urls = append(urls, url)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, url := range urls {
+		stats.SitemapKeyed++
+		if dryRun {
+			continue
+		}
+		if _, err := d.db.Exec(`
+            UPDATE go_sitemaps SET canonical_url = $3
+            WHERE website_id = $1 AND article_url = $2
+        `, d.config.ID, url, canonicalURL(url, d.config)); err != nil {
+			return fmt.Errorf("failed to record canonical URL: %w", err)
+		}
+	}
+	return nil
+}
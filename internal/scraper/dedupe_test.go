@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+func TestGroupDuplicates(t *testing.T) {
+	site := config.WebsiteConfig{BaseURL: "https://blueprint.ng", TrailingSlash: true}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		articles  []articleRow
+		groups    [][]int
+		survivors []int
+		keys      []string
+	}{
+		{
+			"variants of one story",
+			[]articleRow{
+				{id: 1, url: "https://blueprint.ng/story/", canonical: "https://blueprint.ng/story/", lastScraped: day(1)},
+				{id: 2, url: "http://www.blueprint.ng/story/amp/", canonical: "http://www.blueprint.ng/story/amp/", lastScraped: day(5)},
+				{id: 3, url: "https://blueprint.ng/other/", canonical: "https://blueprint.ng/other/", lastScraped: day(1)},
+			},
+			[][]int{{1, 2}, {3}},
+			[]int{1, 3},
+			[]string{"https://blueprint.ng/story/", "https://blueprint.ng/other/"},
+		},
+		{
+			"most recent row survives when none is canonical",
+			[]articleRow{
+				{id: 1, url: "https://blueprint.ng/story/?utm_source=x", canonical: "https://blueprint.ng/story/?utm_source=x", lastScraped: day(1)},
+				{id: 2, url: "https://blueprint.ng/story/amp", canonical: "https://blueprint.ng/story/amp", lastScraped: day(3)},
+			},
+			[][]int{{1, 2}},
+			[]int{2},
+			[]string{"https://blueprint.ng/story/"},
+		},
+		{
+			// Row 1 holds the URL that row 2 is keyed by, but is itself keyed by
+			// its rel=canonical. Grouped separately, renaming row 2 would
+			// collide with row 1.
+			"url of one row is the key of another",
+			[]articleRow{
+				{id: 1, url: "https://blueprint.ng/story/", canonical: "https://blueprint.ng/final-story/", lastScraped: day(1)},
+				{id: 2, url: "https://blueprint.ng/story/?ref=home", canonical: "https://blueprint.ng/story/?ref=home", lastScraped: day(2)},
+				{id: 3, url: "https://blueprint.ng/final-story/amp/", canonical: "https://blueprint.ng/final-story/amp/", lastScraped: day(1)},
+			},
+			[][]int{{1, 2, 3}},
+			[]int{2},
+			[]string{"https://blueprint.ng/story/"},
+		},
+		{
+			"row already under its key wins over a newer one",
+			[]articleRow{
+				{id: 1, url: "https://blueprint.ng/story/?ref=home", canonical: "https://blueprint.ng/final-story/", lastScraped: day(9)},
+				{id: 2, url: "https://blueprint.ng/final-story/", canonical: "https://blueprint.ng/final-story/", lastScraped: day(1)},
+			},
+			[][]int{{1, 2}},
+			[]int{2},
+			[]string{"https://blueprint.ng/final-story/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var groups [][]int
+			var survivors []int
+			var keys []string
+			for _, group := range groupDuplicates(tt.articles, site) {
+				var ids []int
+				for _, row := range group {
+					ids = append(ids, row.id)
+				}
+				groups = append(groups, ids)
+				survivor := pickSurvivor(group)
+				survivors = append(survivors, survivor.id)
+				keys = append(keys, survivor.key)
+			}
+			if !reflect.DeepEqual(groups, tt.groups) {
+				t.Errorf("groups = %v, want %v", groups, tt.groups)
+			}
+			if !reflect.DeepEqual(survivors, tt.survivors) {
+				t.Errorf("survivors = %v, want %v", survivors, tt.survivors)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %q, want %q", keys, tt.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file discovers a website's sitemaps from the Sitemap: lines of its
+// robots.txt and from <sitemapindex> documents, so newly published sitemaps are
+// picked up without editing the configured index range.
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// SitemapKind classifies a discovered sitemap by the content it lists.
+type SitemapKind string
+
+const (
+	SitemapPost     SitemapKind = "post"
+	SitemapNews     SitemapKind = "news"
+	SitemapCategory SitemapKind = "category"
+	SitemapTag      SitemapKind = "tag"
+	SitemapPage     SitemapKind = "page"
+	SitemapOther    SitemapKind = "other"
+)
+
+// maxIndexDepth bounds recursion through nested sitemap indexes.
+const maxIndexDepth = 3
+
+// DiscoveredSitemap is a leaf sitemap found during discovery.
+type DiscoveredSitemap struct {
+	URL     string
+	LastMod string
+	Kind    SitemapKind
+}
+
+// Discover returns every leaf sitemap advertised by the website. It reads
+// the Sitemap: lines of robots.txt, falling back to /sitemap_index.xml and
+// /sitemap.xml, and expands sitemap indexes recursively. Leaf sitemaps are
+// classified by name and are not fetched.
+func (ss *SitemapScraper) Discover() ([]DiscoveredSitemap, error) {
+	roots, err := ss.robotsSitemaps()
+	if err != nil {
+		log.Printf("Could not read robots.txt for %s: %v", ss.config.Name, err)
+	}
+	if len(roots) == 0 {
+		base := strings.TrimSuffix(ss.config.BaseURL, "/")
+		roots = []string{base + "/sitemap_index.xml", base + "/sitemap.xml"}
+	}
+
+	var found []DiscoveredSitemap
+	seen := make(map[string]bool)
+	var lastErr error
+	for _, root := range roots {
+		if err := ss.expandSitemap(root, "", 0, seen, &found); err != nil {
+			log.Printf("Error expanding sitemap %s: %v", root, err)
+			lastErr = err
+		}
+	}
+
+	if len(found) == 0 && lastErr != nil {
+		return nil, fmt.Errorf("sitemap discovery failed: %w", lastErr)
+	}
+	log.Printf("Discovered %d sitemaps for %s", len(found), ss.config.Name)
+	return found, nil
+}
+
+// ArticleSitemapURLs returns the post and news sitemaps found by Discover.
+// When discovery finds none, it falls back to the configured SitemapFormat
+// index range.
+func (ss *SitemapScraper) ArticleSitemapURLs() ([]string, error) {
+	discovered, err := ss.Discover()
+	if err != nil {
+		log.Printf("Sitemap discovery failed for %s: %v", ss.config.Name, err)
+	}
+
+	var urls []string
+	for _, sitemap := range discovered {
+		if sitemap.Kind == SitemapPost || sitemap.Kind == SitemapNews {
+			urls = append(urls, sitemap.URL)
+		}
+	}
+	if len(urls) > 0 {
+		return urls, nil
+	}
+
+	if ss.config.SitemapFormat == "" {
+		return nil, fmt.Errorf("no article sitemaps discovered for %s and no sitemap_format configured", ss.config.Name)
+	}
+	log.Printf("No article sitemaps discovered for %s, using sitemap_format", ss.config.Name)
+	return ss.SitemapURLs(), nil
+}
+
+// robotsSitemaps returns the Sitemap: URLs listed in the site's robots.txt.
+func (ss *SitemapScraper) robotsSitemaps() ([]string, error) {
+	robots, err := ss.fetcher.Robots(ss.config.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return robots.Sitemaps, nil
+}
+
+// expandSitemap fetches sitemapURL and, if it is an index, walks its
+// children. Children that look like indexes themselves are fetched in turn;
+// all others are recorded as leaves without being downloaded.
+func (ss *SitemapScraper) expandSitemap(sitemapURL, lastMod string, depth int, seen map[string]bool, found *[]DiscoveredSitemap) error {
+	if seen[sitemapURL] {
+		return nil
+	}
+	seen[sitemapURL] = true
+
+	resp, err := ss.fetcher.Get(sitemapURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	doc, err := newSitemapReader(resp.Body, ss.config.MaxSitemapBytes, ss.config.MaxSitemapURLs)
+	if err != nil {
+		return err
+	}
+
+	if !doc.isIndex() {
+		*found = append(*found, DiscoveredSitemap{
+			URL:     sitemapURL,
+			LastMod: lastMod,
+			Kind:    classifySitemap(sitemapURL),
+		})
+		return nil
+	}
+
+	for child, err := range doc.Sitemaps() {
+		if err != nil {
+			return err
+		}
+		loc := strings.TrimSpace(child.Loc)
+		if loc == "" || seen[loc] {
+			continue
+		}
+		if isSitemapIndexURL(loc) && depth < maxIndexDepth {
+			if err := ss.expandSitemap(loc, child.LastMod, depth+1, seen, found); err != nil {
+				log.Printf("Error expanding sitemap %s: %v", loc, err)
+			}
+			continue
+		}
+		seen[loc] = true
+		*found = append(*found, DiscoveredSitemap{
+			URL:     loc,
+			LastMod: child.LastMod,
+			Kind:    classifySitemap(loc),
+		})
+	}
+	return nil
+}
+
+// sitemapFileName returns the lower-cased last path segment of a sitemap URL.
+func sitemapFileName(sitemapURL string) string {
+	if u, err := url.Parse(sitemapURL); err == nil {
+		sitemapURL = u.Path
+	}
+	return strings.ToLower(path.Base(sitemapURL))
+}
+
+// isSitemapIndexURL reports whether a sitemap index child is itself an index.
+func isSitemapIndexURL(sitemapURL string) bool {
+	return strings.Contains(sitemapFileName(sitemapURL), "index")
+}
+
+// classifySitemap guesses the kind of a sitemap from its file name, following
+// the naming used by Yoast, Rank Math and the core WordPress sitemaps
+// (post-sitemap12.xml, wp-sitemap-taxonomies-category-1.xml, news-sitemap.xml).
+func classifySitemap(sitemapURL string) SitemapKind {
+	name := sitemapFileName(sitemapURL)
+	switch {
+	case strings.Contains(name, "news"):
+		return SitemapNews
+	case strings.Contains(name, "category"):
+		return SitemapCategory
+	case strings.Contains(name, "tag"):
+		return SitemapTag
+	case strings.Contains(name, "page"):
+		return SitemapPage
+	case strings.Contains(name, "post"):
+		return SitemapPost
+	default:
+		return SitemapOther
+	}
+}
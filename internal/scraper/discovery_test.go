@@ -0,0 +1,38 @@
+package scraper
+
+import "testing"
+
+func TestClassifySitemap(t *testing.T) {
+	tests := []struct {
+		url   string
+		kind  SitemapKind
+		index bool
+	}{
+		{"https://blueprint.ng/post-sitemap12.xml", SitemapPost, false},
+		{"https://blueprint.ng/post-sitemap.xml.gz", SitemapPost, false},
+		{"https://blueprint.ng/news-sitemap.xml", SitemapNews, false},
+		{"https://blueprint.ng/sitemap-news.xml?page=2", SitemapNews, false},
+		{"https://blueprint.ng/category-sitemap.xml", SitemapCategory, false},
+		{"https://blueprint.ng/wp-sitemap-taxonomies-category-1.xml", SitemapCategory, false},
+		{"https://blueprint.ng/post_tag-sitemap.xml", SitemapTag, false},
+		{"https://blueprint.ng/wp-sitemap-taxonomies-post_tag-1.xml", SitemapTag, false},
+		{"https://blueprint.ng/page-sitemap.xml", SitemapPage, false},
+		{"https://blueprint.ng/wp-sitemap-posts-post-3.xml", SitemapPost, false},
+		{"https://blueprint.ng/wp-sitemap-posts-page-1.xml", SitemapPage, false},
+		{"https://blueprint.ng/author-sitemap.xml", SitemapOther, false},
+		{"https://blueprint.ng/NEWS-SITEMAP.XML", SitemapNews, false},
+		{"https://blueprint.ng/post-sitemap/index.xml", SitemapOther, true},
+		{"https://blueprint.ng/sitemap_index.xml", SitemapOther, true},
+		{"https://news.blueprint.ng/sitemap.xml", SitemapOther, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := classifySitemap(tt.url); got != tt.kind {
+				t.Errorf("classifySitemap(%q) = %q, want %q", tt.url, got, tt.kind)
+			}
+			if got := isSitemapIndexURL(tt.url); got != tt.index {
+				t.Errorf("isSitemapIndexURL(%q) = %v, want %v", tt.url, got, tt.index)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file implements the persistent article job queue in go_scrape_jobs. Workers
+// claim jobs with SELECT ... FOR UPDATE SKIP LOCKED, so several processes can share
+// a queue and an interrupted run resumes where it stopped.
+package scraper
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+)
+
+// JobState is the lifecycle state of a scrape job.
+type JobState string
+
+const (
+	JobPending    JobState = "pending"
+	JobInProgress JobState = "in_progress"
+	JobDone       JobState = "done"
+	JobFailed     JobState = "failed"
+	JobSkipped    JobState = "skipped" // Deliberately not fetched, e.g. disallowed by robots.txt
+)
+
+// staleJobAfter is how long a job may stay in_progress before it is assumed
+// to belong to a crashed worker and is handed out again.
+const staleJobAfter = 15 * time.Minute
+
+// Job is a claimed article URL.
+type Job struct {
+	ID       int64
+	URL      string
+	Attempts int // Attempts so far, including the current one
+}
+
+// EnqueueOptions selects which sitemap URLs Enqueue turns into jobs.
+type EnqueueOptions struct {
+	Since time.Time // Only URLs with a sitemap lastmod at or after Since; zero for all
+	Full  bool      // Queue every URL, not just new or changed ones, and retry failed jobs
+}
+
+type JobQueue struct {
+	db     *sql.DB
+	config config.WebsiteConfig
+}
+
+func NewJobQueue(db *sql.DB, config config.WebsiteConfig) *JobQueue {
+	return &JobQueue{
+		db:     db,
+		config: config,
+	}
+}
+
+// Enqueue creates pending jobs for the website's go_sitemaps rows that need
+// scraping. Unless Full is set, a URL needs scraping when it has never been
+// scraped, or when its sitemap lastmod is newer than the last time it was
+// scraped (falling back to the article's last_updated for rows scraped
+// before last_scraped_at existed). URLs marked invalid after a 404, 410 or
+// redirect to the homepage are never queued, even with Full.
+//
+// Jobs already pending or in progress are left alone. Finished jobs are
+// reset to pending when their URL is selected again; failed and skipped
+// jobs only with Full. It returns the number of jobs created or reset.
+func (q *JobQueue) Enqueue(opts EnqueueOptions) (int64, error) {
+	res, err := q.db.Exec(`
+        INSERT INTO go_scrape_jobs (website_id, url, state, priority_at)
+        SELECT s.website_id, s.article_url, 'pending',
+               COALESCE(s.news_publication_date, s.last_mod)
+        FROM go_sitemaps s
+        LEFT JOIN go_articles a ON a.url = COALESCE(s.canonical_url, s.article_url)
+        WHERE s.website_id = $1
+          AND s.is_valid
+          AND ($2::timestamptz IS NULL OR s.last_mod >= $2)
+          AND (
+              $3::boolean
+              OR a.id IS NULL
+              OR COALESCE(a.last_scraped_at, a.last_updated) IS NULL
+              OR s.last_mod > COALESCE(a.last_scraped_at, a.last_updated)
+          )
+        ON CONFLICT (website_id, url) DO UPDATE SET
+            state = 'pending',
+            attempts = 0,
+            last_error = NULL,
+            priority_at = EXCLUDED.priority_at,
+            next_attempt_at = NOW(),
+            updated_at = NOW()
+        WHERE go_scrape_jobs.state = 'done'
+           OR ($3::boolean AND go_scrape_jobs.state IN ('failed', 'skipped'))
+    `, q.config.ID, sql.NullTime{Time: opts.Since, Valid: !opts.Since.IsZero()}, opts.Full)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RecoverStale returns jobs left in_progress by crashed workers to pending.
+func (q *JobQueue) RecoverStale() (int64, error) {
+	res, err := q.db.Exec(`
+        UPDATE go_scrape_jobs
+        SET state = 'pending', claimed_at = NULL, updated_at = NOW()
+        WHERE website_id = $1
+          AND state = 'in_progress'
+          AND claimed_at < NOW() - make_interval(secs => $2)
+    `, q.config.ID, staleJobAfter.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to recover stale jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Claim atomically takes the next due pending job, freshest articles first.
+// It returns nil when no job is currently due.
+func (q *JobQueue) Claim() (*Job, error) {
+	var job Job
+	err := q.db.QueryRow(`
+        UPDATE go_scrape_jobs j
+        SET state = 'in_progress',
+            attempts = j.attempts + 1,
+            claimed_at = NOW(),
+            updated_at = NOW()
+        WHERE j.id = (
+            SELECT id FROM go_scrape_jobs
+            WHERE website_id = $1
+              AND state = 'pending'
+              AND next_attempt_at <= NOW()
+            ORDER BY priority_at DESC NULLS LAST, id
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING j.id, j.url, j.attempts
+    `, q.config.ID).Scan(&job.ID, &job.URL, &job.Attempts)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+// Complete marks a job as done.
+func (q *JobQueue) Complete(job *Job) error {
+	_, err := q.db.Exec(`
+        UPDATE go_scrape_jobs
+        SET state = 'done', last_error = NULL, claimed_at = NULL, updated_at = NOW()
+        WHERE id = $1
+    `, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete job %d: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Fail records a failed attempt. The job is retried after an exponential
+// backoff based on RetryDelay until MaxRetries attempts have been made, after
+// which it is marked failed. Permanent fetch errors such as 404, and pages
+// whose content could not be extracted, fail the job straight away.
+func (q *JobQueue) Fail(job *Job, cause error) error {
+	state := JobPending
+	if job.Attempts >= q.config.MaxRetries || fetch.IsPermanent(cause) || errors.Is(cause, ErrExtractionFailed) {
+		state = JobFailed
+	}
+	delay := retryBackoff(q.config, job.Attempts)
+
+	_, err := q.db.Exec(`
+        UPDATE go_scrape_jobs
+        SET state = $2,
+            last_error = $3,
+            next_attempt_at = NOW() + make_interval(secs => $4),
+            claimed_at = NULL,
+            updated_at = NOW()
+        WHERE id = $1
+    `, job.ID, state, cause.Error(), delay.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record failure of job %d: %w", job.ID, err)
+	}
+
+	if state == JobFailed {
+		log.Printf("Giving up on %s after %d attempts: %v", job.URL, job.Attempts, cause)
+	}
+	return nil
+}
+
+// Skip marks a job as deliberately not fetched and records why.
+func (q *JobQueue) Skip(job *Job, reason string) error {
+	_, err := q.db.Exec(`
+        UPDATE go_scrape_jobs
+        SET state = 'skipped', last_error = $2, claimed_at = NULL, updated_at = NOW()
+        WHERE id = $1
+    `, job.ID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to skip job %d: %w", job.ID, err)
+	}
+	log.Printf("Skipping %s: %s", job.URL, reason)
+	return nil
+}
+
+// Counts returns the number of the website's jobs in each state.
+func (q *JobQueue) Counts() (map[JobState]int, error) {
+	rows, err := q.db.Query(`
+        SELECT state, COUNT(*) FROM go_scrape_jobs
+        WHERE website_id = $1
+        GROUP BY state
+    `, q.config.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[JobState]int)
+	for rows.Next() {
+		var state JobState
+		var n int
+		if err := rows.Scan(&state, &n); err != nil {
+			return nil, err
+		}
+		counts[state] = n
+	}
+	return counts, rows.Err()
+}
+
+// retryBackoff doubles RetryDelay (at least one second) with every attempt.
+func retryBackoff(cfg config.WebsiteConfig, attempts int) time.Duration {
+	delay := time.Duration(max(cfg.RetryDelay, 1)) * time.Second
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return delay
+}
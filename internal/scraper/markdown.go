@@ -0,0 +1,260 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file renders an article body as sanitised Markdown, keeping the subheadings,
+// lists, quotes, code and links that plain paragraph text loses. The plain-text
+// Content is rendered from the same blocks, so both always describe the same body.
+package scraper
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// blockContent matches the elements rendered as Markdown blocks.
+const blockContent = "p, h1, h2, h3, h4, h5, h6, ul, ol, blockquote, pre"
+
+// contentBlock is one rendered paragraph, heading, list, quote or code block.
+type contentBlock struct {
+	markdown string
+	text     string
+}
+
+// renderContent renders the block-level children of containers, skipping
+// containers that look like page furniture. Wrapper elements such as div and
+// section are descended into unless they look like page furniture too;
+// everything else (scripts, forms, embeds) is dropped.
+func renderContent(containers *goquery.Selection, pageURL string) (markdown, text string) {
+	base, _ := url.Parse(pageURL)
+	var blocks []contentBlock
+	containers.Each(func(i int, c *goquery.Selection) {
+		if c.Is(boilerplate) || isBoilerplate(c) {
+			return
+		}
+		blocks = append(blocks, renderBlocks(c.Children(), base)...)
+	})
+
+	var md, plain []string
+	for _, b := range blocks {
+		md = append(md, b.markdown)
+		plain = append(plain, b.text)
+	}
+	return strings.Join(md, "\n\n"), strings.Join(plain, "\n\n")
+}
+
+// outermost drops the elements of sel that sit inside another element of
+// sel, so no part of the page is rendered twice.
+func outermost(sel *goquery.Selection) *goquery.Selection {
+	return sel.NotSelection(sel.Find("*"))
+}
+
+func renderBlocks(nodes *goquery.Selection, base *url.URL) []contentBlock {
+	var blocks []contentBlock
+	nodes.Each(func(i int, s *goquery.Selection) {
+		if s.Is(boilerplate) || isBoilerplate(s) {
+			return
+		}
+		switch name := goquery.NodeName(s); name {
+		case "p":
+			if md, text := renderInline(s, base); text != "" {
+				blocks = append(blocks, contentBlock{md, text})
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			// The article title is the only h1, so body headings start at ##.
+			level := max(int(name[1]-'0'), 2)
+			if md, text := renderInline(s, base); text != "" {
+				blocks = append(blocks, contentBlock{strings.Repeat("#", level) + " " + md, text})
+			}
+		case "ul", "ol":
+			if b, ok := renderList(s, base, 0); ok {
+				blocks = append(blocks, b)
+			}
+		case "blockquote":
+			inner := renderBlocks(s.Children(), base)
+			if len(inner) == 0 {
+				if md, text := renderInline(s, base); text != "" {
+					inner = []contentBlock{{md, text}}
+				}
+			}
+			var md, plain []string
+			for _, b := range inner {
+				md = append(md, b.markdown)
+				plain = append(plain, b.text)
+			}
+			if len(md) > 0 {
+				quoted := "> " + strings.ReplaceAll(strings.Join(md, "\n\n"), "\n", "\n> ")
+				quoted = strings.ReplaceAll(quoted, "\n> \n", "\n>\n")
+				blocks = append(blocks, contentBlock{quoted, strings.Join(plain, "\n\n")})
+			}
+		case "pre":
+			if code := strings.Trim(s.Text(), "\n"); strings.TrimSpace(code) != "" {
+				blocks = append(blocks, contentBlock{"```\n" + code + "\n```", code})
+			}
+		case "div", "section", "article", "main", "center":
+			if s.Find(blockContent).Length() > 0 {
+				blocks = append(blocks, renderBlocks(s.Children(), base)...)
+			} else if md, text := renderInline(s, base); text != "" {
+				// Some themes wrap bare text in divs instead of paragraphs.
+				blocks = append(blocks, contentBlock{md, text})
+			}
+		}
+	})
+	return blocks
+}
+
+// renderList renders a ul or ol, indenting nested lists two spaces per level.
+func renderList(list *goquery.Selection, base *url.URL, depth int) (contentBlock, bool) {
+	ordered := goquery.NodeName(list) == "ol"
+	indent := strings.Repeat("  ", depth)
+	var md, plain []string
+	n := 0
+	list.ChildrenFiltered("li").Each(func(i int, li *goquery.Selection) {
+		item := li.Clone()
+		item.Find("ul, ol").Remove()
+		itemMD, itemText := renderInline(item, base)
+		if itemText != "" {
+			n++
+			marker := "- "
+			if ordered {
+				marker = strconv.Itoa(n) + ". "
+			}
+			md = append(md, indent+marker+itemMD)
+			plain = append(plain, itemText)
+		}
+		li.ChildrenFiltered("ul, ol").Each(func(j int, nested *goquery.Selection) {
+			if b, ok := renderList(nested, base, depth+1); ok {
+				md = append(md, b.markdown)
+				plain = append(plain, b.text)
+			}
+		})
+	})
+	if len(md) == 0 {
+		return contentBlock{}, false
+	}
+	return contentBlock{strings.Join(md, "\n"), strings.Join(plain, "\n")}, true
+}
+
+// renderInline renders the inline content of s: text with emphasis, code
+// and links kept as Markdown, and the same text without markup.
+func renderInline(s *goquery.Selection, base *url.URL) (markdown, text string) {
+	var md, plain strings.Builder
+	for _, node := range s.Nodes {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			renderNode(child, base, &md, &plain)
+		}
+	}
+	return collapseSpace(md.String()), collapseSpace(plain.String())
+}
+
+func renderNode(n *html.Node, base *url.URL, md, plain *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		md.WriteString(escapeMarkdown(n.Data))
+		plain.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	children := func(md, plain *strings.Builder) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			renderNode(child, base, md, plain)
+		}
+	}
+	// wrap renders the children between Markdown delimiters, dropping the
+	// delimiters when the children render to nothing.
+	wrap := func(open, close string) {
+		var innerMD strings.Builder
+		children(&innerMD, plain)
+		if inner := strings.TrimSpace(innerMD.String()); inner != "" {
+			md.WriteString(open + inner + close)
+		}
+	}
+
+	switch n.Data {
+	case "script", "style", "noscript", "iframe", "form", "button", "svg", "img":
+	case "br":
+		md.WriteString("\n")
+		plain.WriteString("\n")
+	case "strong", "b":
+		wrap("**", "**")
+	case "em", "i":
+		wrap("*", "*")
+	case "code":
+		code := strings.TrimSpace(nodeText(n))
+		if code != "" {
+			md.WriteString("`" + code + "`")
+			plain.WriteString(code)
+		}
+	case "a":
+		href := linkTarget(n, base)
+		if href == "" {
+			children(md, plain)
+			return
+		}
+		var label strings.Builder
+		children(&label, plain)
+		if inner := strings.TrimSpace(label.String()); inner != "" {
+			md.WriteString("[" + inner + "](" + href + ")")
+		}
+	default:
+		children(md, plain)
+	}
+}
+
+// linkTarget returns the absolute http(s) URL of an a element, or "" for
+// anchors, javascript: and mailto: links.
+func linkTarget(n *html.Node, base *url.URL) string {
+	for _, attr := range n.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		link, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil {
+			return ""
+		}
+		if base != nil {
+			link = base.ResolveReference(link)
+		}
+		if link.Scheme != "http" && link.Scheme != "https" {
+			return ""
+		}
+		return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(link.String())
+	}
+	return ""
+}
+
+// nodeText returns the text of n and its descendants, unescaped.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var text strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(nodeText(child))
+	}
+	return text.String()
+}
+
+// markdownEscaper escapes characters that would otherwise start Markdown
+// markup inside text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// collapseSpace folds runs of whitespace into single spaces while keeping
+// explicit line breaks.
+func collapseSpace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
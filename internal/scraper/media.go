@@ -0,0 +1,262 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file collects an article's images: the featured image named by JSON-LD and
+// og:image, and the photos inside the body with their alt text, figcaption caption,
+// photo credit and dimensions. They are stored in go_article_media, and when
+// ThumbnailDir is set each image is downloaded once and kept as a thumbnail.
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ArticleMedia is one image of an article.
+type ArticleMedia struct {
+	URL      string
+	Alt      string
+	Caption  string
+	Credit   string
+	Width    int // 0 when unknown
+	Height   int
+	Featured bool   // The lead image named by the page metadata
+	Source   string // SourceJSONLD, SourceMeta, SourceFigure or SourceImg
+}
+
+// Sources of body images.
+const (
+	SourceFigure = "figure"
+	SourceImg    = "img"
+)
+
+// minInlineImageSize skips icons, avatars and tracking pixels in the body.
+const minInlineImageSize = 100
+
+// creditPattern splits a trailing "Photo: NAME" style credit off a caption.
+var creditPattern = regexp.MustCompile(`(?i)[\s(|–-]*\b(?:photo|photograph|image|picture|credit|source)s?\s*(?:credit)?\s*:\s*([^)]+?)\)?\s*$`)
+
+// jsonImages returns the images of a schema.org image value: a URL, an
+// ImageObject, or an array of either.
+func jsonImages(v any) []ArticleMedia {
+	switch v := v.(type) {
+	case string:
+		if u := strings.TrimSpace(v); u != "" {
+			return []ArticleMedia{{URL: u}}
+		}
+	case map[string]any:
+		u := jsonString(v["url"])
+		if u == "" {
+			u = jsonString(v["contentUrl"])
+		}
+		if u == "" {
+			return nil
+		}
+		m := ArticleMedia{
+			URL:     u,
+			Caption: jsonString(v["caption"]),
+			Width:   jsonInt(v["width"]),
+			Height:  jsonInt(v["height"]),
+		}
+		if credit := jsonString(v["creditText"]); credit != "" {
+			m.Credit = credit
+		} else if names := jsonNames(v["copyrightHolder"]); len(names) > 0 {
+			m.Credit = strings.Join(names, ", ")
+		} else if names := jsonNames(v["author"]); len(names) > 0 {
+			m.Credit = strings.Join(names, ", ")
+		}
+		return []ArticleMedia{m}
+	case []any:
+		var out []ArticleMedia
+		for _, item := range v {
+			out = append(out, jsonImages(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonInt reads a dimension given as a number, a numeric string such as
+// "1200" or "1200px", or a QuantitativeValue.
+func jsonInt(v any) int {
+	switch v := v.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px"))
+		return n
+	case map[string]any:
+		return jsonInt(v["value"])
+	}
+	return 0
+}
+
+// bodyImages collects the figures and standalone images inside the
+// article body.
+func bodyImages(root *goquery.Selection) []ArticleMedia {
+	var images []ArticleMedia
+	root.Find("figure, img").Each(func(i int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "img" {
+			if s.ParentsFiltered("figure").Length() > 0 {
+				return // Handled with its figure
+			}
+			if m, ok := imageElement(s); ok {
+				m.Source = SourceImg
+				images = append(images, m)
+			}
+			return
+		}
+
+		m, ok := imageElement(s.Find("img").First())
+		if !ok {
+			return
+		}
+		m.Source = SourceFigure
+		caption := s.Find("figcaption").First().Clone()
+		if credit := caption.Find(`[class*="credit"]`).First(); credit.Length() > 0 {
+			m.Credit = collapseSpace(credit.Text())
+			credit.Remove()
+		}
+		m.Caption = collapseSpace(caption.Text())
+		if m.Credit == "" {
+			if match := creditPattern.FindStringSubmatchIndex(m.Caption); match != nil {
+				m.Credit = strings.TrimSpace(m.Caption[match[2]:match[3]])
+				m.Caption = strings.TrimSpace(m.Caption[:match[0]])
+			}
+		}
+		images = append(images, m)
+	})
+	return images
+}
+
+// imageElement reads an img element, following the lazy-loading attributes
+// themes use in place of src. It rejects data: URIs and images declared
+// smaller than minInlineImageSize.
+func imageElement(img *goquery.Selection) (ArticleMedia, bool) {
+	if img.Length() == 0 {
+		return ArticleMedia{}, false
+	}
+	var src string
+	for _, attr := range []string{"data-src", "data-lazy-src", "data-original", "src"} {
+		if value, ok := img.Attr(attr); ok && value != "" && !strings.HasPrefix(value, "data:") {
+			src = strings.TrimSpace(value)
+			break
+		}
+	}
+	if src == "" {
+		return ArticleMedia{}, false
+	}
+
+	m := ArticleMedia{URL: src}
+	m.Alt, _ = img.Attr("alt")
+	m.Alt = strings.TrimSpace(m.Alt)
+	if w, ok := img.Attr("width"); ok {
+		m.Width, _ = strconv.Atoi(strings.TrimSuffix(w, "px"))
+	}
+	if h, ok := img.Attr("height"); ok {
+		m.Height, _ = strconv.Atoi(strings.TrimSuffix(h, "px"))
+	}
+	if (m.Width > 0 && m.Width < minInlineImageSize) || (m.Height > 0 && m.Height < minInlineImageSize) {
+		return ArticleMedia{}, false
+	}
+	return m, true
+}
+
+// mergeMedia resolves image URLs against the page and merges entries for
+// the same image, keeping the first non-empty value of every field, so the
+// featured image found in JSON-LD picks up its caption from the body.
+func mergeMedia(pageURL string, groups ...[]ArticleMedia) []ArticleMedia {
+	base, _ := url.Parse(pageURL)
+	var out []ArticleMedia
+	index := make(map[string]int)
+	for _, group := range groups {
+		for _, m := range group {
+			if base != nil {
+				if ref, err := url.Parse(m.URL); err == nil {
+					m.URL = base.ResolveReference(ref).String()
+				}
+			}
+			if !strings.HasPrefix(m.URL, "http://") && !strings.HasPrefix(m.URL, "https://") {
+				continue
+			}
+			i, ok := index[m.URL]
+			if !ok {
+				index[m.URL] = len(out)
+				out = append(out, m)
+				continue
+			}
+			existing := &out[i]
+			existing.Featured = existing.Featured || m.Featured
+			for _, field := range []struct{ dst, src *string }{
+				{&existing.Alt, &m.Alt}, {&existing.Caption, &m.Caption}, {&existing.Credit, &m.Credit},
+			} {
+				if *field.dst == "" {
+					*field.dst = *field.src
+				}
+			}
+			if existing.Width == 0 && existing.Height == 0 {
+				existing.Width, existing.Height = m.Width, m.Height
+			}
+		}
+	}
+	return out
+}
+
+// SaveThumbnails downloads the saved article's images that have no
+// thumbnail yet and records the hash of each. It does nothing unless
+// ThumbnailDir is configured. A failed image is logged and retried the next
+// time the article is saved.
+func (as *ArticleScraper) SaveThumbnails(article *Article) error {
+	if as.thumbs == nil || article.ID == 0 {
+		return nil
+	}
+	rows, err := as.db.Query(`
+		SELECT url FROM go_article_media
+		WHERE article_id = $1 AND thumbnail_sha256 IS NULL
+		ORDER BY position
+	`, article.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load article images: %w", err)
+	}
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			rows.Close()
+			return err
+		}
+		urls = append(urls, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, u := range urls {
+		hash, err := as.saveThumbnail(u)
+		if err != nil {
+			log.Printf("Error saving thumbnail for %s: %v", u, err)
+			continue
+		}
+		if _, err := as.db.Exec(`
+			UPDATE go_article_media SET thumbnail_sha256 = $3, updated_at = NOW()
+			WHERE article_id = $1 AND url = $2
+		`, article.ID, u, hash); err != nil {
+			return fmt.Errorf("failed to record thumbnail: %w", err)
+		}
+	}
+	return nil
+}
+
+func (as *ArticleScraper) saveThumbnail(imageURL string) (string, error) {
+	resp, err := as.fetcher.Get(imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return as.thumbs.Save(resp.Body)
+}
@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestBodyImages(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []ArticleMedia
+	}{
+		{
+			"figure with credit element",
+			`<figure><img src="/a.jpg" alt="Senate" width="800" height="450"><figcaption>Senators at plenary <span class="photo-credit">NAN</span></figcaption></figure>`,
+			[]ArticleMedia{{URL: "/a.jpg", Alt: "Senate", Caption: "Senators at plenary", Credit: "NAN", Width: 800, Height: 450, Source: SourceFigure}},
+		},
+		{
+			"credit in caption text",
+			`<figure><img src="/b.jpg"><figcaption>President Tinubu in Abuja. Photo: State House</figcaption></figure>`,
+			[]ArticleMedia{{URL: "/b.jpg", Caption: "President Tinubu in Abuja.", Credit: "State House", Source: SourceFigure}},
+		},
+		{
+			"bracketed credit",
+			`<figure><img src="/c.jpg"><figcaption>Flooded road (Photo credit: Blueprint)</figcaption></figure>`,
+			[]ArticleMedia{{URL: "/c.jpg", Caption: "Flooded road", Credit: "Blueprint", Source: SourceFigure}},
+		},
+		{
+			"lazy-loaded image",
+			`<p><img src="data:image/gif;base64,R0lGOD" data-lazy-src="/d.jpg" alt="Market" width="600px"></p>`,
+			[]ArticleMedia{{URL: "/d.jpg", Alt: "Market", Width: 600, Source: SourceImg}},
+		},
+		{
+			"icons and placeholders skipped",
+			`<img src="/icon.png" width="16" height="16"><img src="data:image/gif;base64,R0lGOD"><figure><figcaption>No image</figcaption></figure>`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<div>" + tt.html + "</div>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bodyImages(doc.Find("div").First()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bodyImages =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeMedia(t *testing.T) {
+	const page = "https://blueprint.ng/story/"
+	jsonLD := []ArticleMedia{{URL: "https://blueprint.ng/a.jpg", Width: 1200, Height: 675, Credit: "Blueprint", Featured: true, Source: SourceJSONLD}}
+	meta := []ArticleMedia{{URL: "https://blueprint.ng/a.jpg", Alt: "Featured alt", Width: 800, Height: 450, Featured: true, Source: SourceMeta}}
+	inline := []ArticleMedia{
+		{URL: "/a.jpg", Alt: "Inline alt", Caption: "Senators at plenary", Credit: "NAN", Source: SourceFigure},
+		{URL: "b.jpg", Alt: "Market", Source: SourceImg},
+		{URL: "javascript:void(0)", Source: SourceImg},
+	}
+
+	want := []ArticleMedia{
+		{URL: "https://blueprint.ng/a.jpg", Alt: "Featured alt", Caption: "Senators at plenary", Credit: "Blueprint",
+			Width: 1200, Height: 675, Featured: true, Source: SourceJSONLD},
+		{URL: "https://blueprint.ng/story/b.jpg", Alt: "Market", Source: SourceImg},
+	}
+	if got := mergeMedia(page, jsonLD, meta, inline); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeMedia =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Dimensions are taken from a later entry when the first has none.
+	got := mergeMedia(page, []ArticleMedia{{URL: "/c.jpg", Featured: true}}, []ArticleMedia{{URL: "/c.jpg", Width: 640, Height: 360}})
+	if len(got) != 1 || got[0].Width != 640 || got[0].Height != 360 || !got[0].Featured {
+		t.Errorf("mergeMedia = %+v, want one featured 640x360 image", got)
+	}
+}
@@ -0,0 +1,321 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file reads the structured metadata publishers embed for search engines and
+// social networks: schema.org JSON-LD and OpenGraph/article:* meta tags. It is
+// far more stable across theme changes than CSS selectors, which only serve as
+// the fallback.
+package scraper
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+// Field sources recorded in Article.FieldSources.
+const (
+	SourceJSONLD   = "json-ld"
+	SourceMeta     = "meta"
+	SourceSelector = "selector"
+)
+
+// pageMetadata holds the article fields one source provided. Dates are kept
+// as strings and parsed when a field is chosen.
+type pageMetadata struct {
+	source    string
+	title     string
+	authors   []ArticleAuthor // Bylines as given; split by normaliseAuthors
+	published string
+	updated   string
+	section   string
+	keywords  []string
+	image     string
+	images    []ArticleMedia // Featured images, with whatever detail the source gives
+}
+
+// articleTypes are the schema.org types treated as the article itself.
+var articleTypes = map[string]bool{
+	"Article":              true,
+	"NewsArticle":          true,
+	"ReportageNewsArticle": true,
+	"AnalysisNewsArticle":  true,
+	"OpinionNewsArticle":   true,
+	"BlogPosting":          true,
+}
+
+// readJSONLD returns the first schema.org article object in the page's
+// JSON-LD blocks, looking inside arrays and Yoast-style @graph lists.
+func readJSONLD(doc *goquery.Document) *pageMetadata {
+	meta := &pageMetadata{source: SourceJSONLD}
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return true
+		}
+		node := findArticleNode(data)
+		if node == nil {
+			return true
+		}
+		meta.title = jsonString(node["headline"])
+		meta.authors = jsonAuthors(node["author"])
+		meta.published = jsonString(node["datePublished"])
+		meta.updated = jsonString(node["dateModified"])
+		if sections := jsonStrings(node["articleSection"]); len(sections) > 0 {
+			meta.section = sections[0]
+		}
+		meta.keywords = splitKeywords(jsonStrings(node["keywords"]))
+		meta.image = jsonURL(node["image"])
+		for _, m := range jsonImages(node["image"]) {
+			m.Featured, m.Source = true, SourceJSONLD
+			meta.images = append(meta.images, m)
+		}
+		return false
+	})
+	return meta
+}
+
+func findArticleNode(data any) map[string]any {
+	return findJSONNode(data, articleTypes)
+}
+
+// findJSONNode returns the first object whose @type is one of types,
+// looking inside arrays and @graph lists.
+func findJSONNode(data any, types map[string]bool) map[string]any {
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			if node := findJSONNode(item, types); node != nil {
+				return node
+			}
+		}
+	case map[string]any:
+		for _, t := range jsonStrings(v["@type"]) {
+			if types[t] {
+				return v
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findJSONNode(graph, types)
+		}
+	}
+	return nil
+}
+
+// jsonString returns v if it is a string.
+func jsonString(v any) string {
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+// jsonStrings returns v as a list of strings, accepting a single string or
+// an array.
+func jsonStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			return []string{s}
+		}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s := jsonString(item); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonNames returns the names of a Person/Organization value, which may be
+// a plain string, an object with a name, or an array of either.
+func jsonNames(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return jsonStrings(v)
+	case map[string]any:
+		return jsonStrings(v["name"])
+	case []any:
+		var out []string
+		for _, item := range v {
+			out = append(out, jsonNames(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonAuthors returns the names and profile URLs of a schema.org author
+// value: a name, a Person or Organization, or an array of either.
+func jsonAuthors(v any) []ArticleAuthor {
+	switch v := v.(type) {
+	case string:
+		if name := strings.TrimSpace(v); name != "" {
+			return []ArticleAuthor{{Name: name}}
+		}
+	case map[string]any:
+		if name := jsonString(v["name"]); name != "" {
+			return []ArticleAuthor{{Name: name, ProfileURL: jsonString(v["url"])}}
+		}
+	case []any:
+		var out []ArticleAuthor
+		for _, item := range v {
+			out = append(out, jsonAuthors(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonURL returns the URL of an ImageObject value, which may be a plain
+// string, an object with a url, or an array of either.
+func jsonURL(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		return jsonString(v["url"])
+	case []any:
+		for _, item := range v {
+			if url := jsonURL(item); url != "" {
+				return url
+			}
+		}
+	}
+	return ""
+}
+
+// splitKeywords splits comma-separated keyword strings and drops blanks
+// and duplicates.
+func splitKeywords(values []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, keyword := range strings.Split(value, ",") {
+			keyword = strings.TrimSpace(keyword)
+			if keyword != "" && !seen[strings.ToLower(keyword)] {
+				seen[strings.ToLower(keyword)] = true
+				out = append(out, keyword)
+			}
+		}
+	}
+	return out
+}
+
+// readMetaTags reads the OpenGraph and article:* meta tags.
+func readMetaTags(doc *goquery.Document) *pageMetadata {
+	content := func(selector string) string {
+		value, _ := doc.Find(selector).First().Attr("content")
+		return strings.TrimSpace(value)
+	}
+
+	meta := &pageMetadata{
+		source:    SourceMeta,
+		title:     trimSiteName(content(`meta[property="og:title"]`), content(`meta[property="og:site_name"]`)),
+		published: content(`meta[property="article:published_time"]`),
+		updated:   content(`meta[property="article:modified_time"]`),
+		section:   content(`meta[property="article:section"]`),
+		image:     content(`meta[property="og:image"]`),
+	}
+
+	// article:author is usually a profile URL, which is no use as a name
+	// but is kept as the profile of a lone named author.
+	author := ArticleAuthor{Name: content(`meta[name="author"]`)}
+	if value := content(`meta[property="article:author"]`); strings.Contains(value, "://") {
+		author.ProfileURL = value
+	} else if author.Name == "" {
+		author.Name = value
+	}
+	if author.Name != "" {
+		meta.authors = []ArticleAuthor{author}
+	}
+
+	var keywords []string
+	doc.Find(`meta[property="article:tag"]`).Each(func(i int, s *goquery.Selection) {
+		if value, ok := s.Attr("content"); ok {
+			keywords = append(keywords, value)
+		}
+	})
+	if len(keywords) == 0 {
+		keywords = append(keywords, content(`meta[name="keywords"]`))
+	}
+	meta.keywords = splitKeywords(keywords)
+
+	if meta.image != "" {
+		width, _ := strconv.Atoi(content(`meta[property="og:image:width"]`))
+		height, _ := strconv.Atoi(content(`meta[property="og:image:height"]`))
+		meta.images = []ArticleMedia{{
+			URL:      meta.image,
+			Alt:      content(`meta[property="og:image:alt"]`),
+			Width:    width,
+			Height:   height,
+			Featured: true,
+			Source:   SourceMeta,
+		}}
+	}
+	return meta
+}
+
+// titleSeparators separate a page title from the site name appended to it.
+var titleSeparators = []string{"-", "|", "–", "—", ":", "·", "•"}
+
+// trimSiteName removes a site name appended to title, as in
+// "Tinubu signs bill - Blueprint Newspapers Limited".
+func trimSiteName(title, siteName string) string {
+	rest, ok := strings.CutSuffix(title, siteName)
+	if siteName == "" || !ok {
+		return title
+	}
+	rest = strings.TrimSpace(rest)
+	for _, sep := range titleSeparators {
+		if trimmed, ok := strings.CutSuffix(rest, sep); ok && strings.TrimSpace(trimmed) != "" {
+			return strings.TrimSpace(trimmed)
+		}
+	}
+	return title
+}
+
+// readSelectors extracts the fields covered by the website's CSS selectors.
+func readSelectors(doc *goquery.Document, selectors config.Selectors) *pageMetadata {
+	meta := &pageMetadata{
+		source: SourceSelector,
+		title:  strings.TrimSpace(findFirst(doc, selectors.Title).First().Text()),
+	}
+	// Co-bylines match the author selector once per author.
+	findFirst(doc, selectors.Author).Each(func(i int, s *goquery.Selection) {
+		author := ArticleAuthor{Name: strings.TrimSpace(s.Text())}
+		link := s
+		if goquery.NodeName(s) != "a" {
+			link = s.Find("a[href]").First()
+		}
+		author.ProfileURL, _ = link.Attr("href")
+		if author.Name != "" {
+			meta.authors = append(meta.authors, author)
+		}
+	})
+	meta.published = dateText(findFirst(doc, selectors.Published).First())
+	meta.updated = dateText(findFirst(doc, selectors.Updated).First())
+	return meta
+}
+
+// dateText reads a date element's datetime attribute, falling back to the
+// text shown to readers, such as "January 5, 2024" or "2 hours ago".
+func dateText(s *goquery.Selection) string {
+	if value, ok := s.Attr("datetime"); ok && strings.TrimSpace(value) != "" {
+		return value
+	}
+	return strings.TrimSpace(s.Text())
+}
+
+// firstField returns the value of the first source for which get returns a
+// non-empty string, and that source's name.
+func firstField(sources []*pageMetadata, get func(*pageMetadata) string) (string, string) {
+	for _, meta := range sources {
+		if value := get(meta); value != "" {
+			return value, meta.source
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+)
+
+const metadataPage = `<html><head>
+<meta property="og:site_name" content="Blueprint Newspapers Limited">
+<meta property="og:title" content="Tinubu signs bill - Blueprint Newspapers Limited">
+<meta property="og:image" content="https://blueprint.ng/wp-content/uploads/tinubu.jpg">
+<meta property="article:section" content="Politics">
+<meta property="article:modified_time" content="2024-01-06T08:00:00+01:00">
+<meta property="article:tag" content="Budget">
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+	{"@type": "WebPage", "name": "Tinubu signs bill"},
+	{"@type": "NewsArticle", "datePublished": "2024-01-05T10:00:00+01:00", "keywords": "Tinubu, Budget, Tinubu"}
+]}</script>
+</head><body><article>
+<h1 class="entry-title">Tinubu signs 2024 budget bill</h1>
+<span class="author vcard"><a href="https://blueprint.ng/author/ade-bello/">Ade Bello</a></span>
+<time class="entry-date published" datetime="2024-01-04T10:00:00+01:00">January 4, 2024</time>
+<div class="entry-content"><p>The President signed the bill on Friday.</p></div>
+</article></body></html>`
+
+func TestExtractArticleMetadata(t *testing.T) {
+	as := &ArticleScraper{config: config.WebsiteConfig{
+		BaseURL: "https://blueprint.ng",
+		Selectors: config.Selectors{
+			Title:     []string{"h1.entry-title"},
+			Author:    []string{"span.author.vcard a"},
+			Published: []string{"time.entry-date.published"},
+			Content:   []string{"div.entry-content"},
+		},
+	}}
+	article, err := as.extractArticle("https://blueprint.ng/tinubu-signs-bill/", []byte(metadataPage), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field  string
+		value  string
+		source string
+	}{
+		{"title", "Tinubu signs 2024 budget bill", SourceSelector},
+		{"author", "Ade Bello", SourceSelector},
+		{"section", "Politics", SourceMeta},
+		{"image", "https://blueprint.ng/wp-content/uploads/tinubu.jpg", SourceMeta},
+		{"published", "2024-01-05T09:00:00Z", SourceJSONLD},
+		{"updated", "2024-01-06T07:00:00Z", SourceMeta},
+		{"keywords", "Tinubu, Budget", SourceJSONLD},
+		{"content", "The President signed the bill on Friday.", SourceSelector},
+	}
+	values := map[string]string{
+		"title":     article.Title,
+		"author":    article.Author,
+		"section":   article.Section,
+		"image":     article.ImageURL,
+		"published": article.PublishDate.Format(time.RFC3339),
+		"updated":   article.UpdatedDate.Format(time.RFC3339),
+		"keywords":  strings.Join(article.Keywords, ", "),
+		"content":   article.Content,
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := values[tt.field]; got != tt.value {
+				t.Errorf("%s = %q, want %q", tt.field, got, tt.value)
+			}
+			if got := article.FieldSources[tt.field]; got != tt.source {
+				t.Errorf("%s source = %q, want %q", tt.field, got, tt.source)
+			}
+		})
+	}
+}
+
+func TestExtractArticleTitle(t *testing.T) {
+	const (
+		jsonLD = `<script type="application/ld+json">{"@type": "NewsArticle", "headline": "Headline from JSON-LD"}</script>`
+		og     = `<meta property="og:site_name" content="Blueprint"><meta property="og:title" content="Share title | Blueprint">`
+		h1     = `<h1 class="entry-title">Heading on the page</h1>`
+	)
+	tests := []struct {
+		name   string
+		head   string
+		body   string
+		title  string
+		source string
+	}{
+		{"json-ld first", jsonLD + og, h1, "Headline from JSON-LD", SourceJSONLD},
+		{"selector before og:title", og, h1, "Heading on the page", SourceSelector},
+		{"og:title without site name", og, "", "Share title", SourceMeta},
+		{"og:title without separator", `<meta property="og:site_name" content="Blueprint"><meta property="og:title" content="Blueprint">`, "", "Blueprint", SourceMeta},
+		{"none", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &ArticleScraper{config: config.WebsiteConfig{
+				BaseURL:   "https://blueprint.ng",
+				Selectors: config.Selectors{Title: []string{"h1.entry-title"}},
+			}}
+			page := "<html><head>" + tt.head + "</head><body>" + tt.body + "</body></html>"
+			article, err := as.extractArticle("https://blueprint.ng/story/", []byte(page), time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if article.Title != tt.title || article.FieldSources["title"] != tt.source {
+				t.Errorf("title = %q (%s), want %q (%s)", article.Title, article.FieldSources["title"], tt.title, tt.source)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file is a small Readability-style extractor used when the configured content
+// selectors find too little text. It scores blocks by the paragraph text they
+// contain, penalises link-heavy blocks such as menus and related-post lists, and
+// picks the best block for rendering.
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SourceReadability marks content found by the fallback extractor.
+const SourceReadability = "readability"
+
+// boilerplate matches elements that never hold article text.
+const boilerplate = "script, style, noscript, template, iframe, form, nav, header, footer, aside, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [aria-hidden=true]"
+
+// boilerplateHints are class or id fragments of comment, share and related
+// blocks.
+var boilerplateHints = []string{
+	"comment", "share", "social", "related", "sidebar", "widget", "newsletter",
+	"subscribe", "advert", "promo", "breadcrumb", "footer", "menu", "popup",
+}
+
+// minParagraphLength is the shortest paragraph that counts towards a score.
+const minParagraphLength = 25
+
+// readableBlock returns the element holding the main text of the page, with
+// boilerplate removed, or nil when no block looks like an article body.
+func readableBlock(doc *goquery.Document) *goquery.Selection {
+	body := doc.Find("body").First().Clone()
+	body.Find(boilerplate).Remove()
+	body.Find("*").Each(func(i int, s *goquery.Selection) {
+		if isBoilerplate(s) {
+			s.Remove()
+		}
+	})
+
+	// Every paragraph adds to its parent's score and half as much to its
+	// grandparent's, so the block wrapping the body text wins.
+	scores := make(map[*goquery.Selection]float64)
+	var candidates []*goquery.Selection
+	nodes := make(map[any]*goquery.Selection)
+	candidate := func(s *goquery.Selection) *goquery.Selection {
+		if s.Length() == 0 {
+			return nil
+		}
+		node := s.Get(0)
+		if c, ok := nodes[node]; ok {
+			return c
+		}
+		nodes[node] = s
+		candidates = append(candidates, s)
+		return s
+	}
+
+	body.Find("p, pre, blockquote").Each(func(i int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < minParagraphLength {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		if parent := candidate(p.Parent()); parent != nil {
+			scores[parent] += score
+		}
+		if grandparent := candidate(p.Parent().Parent()); grandparent != nil {
+			scores[grandparent] += score / 2
+		}
+	})
+
+	var best *goquery.Selection
+	bestScore := 0.0
+	for _, c := range candidates {
+		score := scores[c] * (1 - linkDensity(c))
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// isBoilerplate reports whether an element's class or id marks it as
+// page furniture rather than content.
+func isBoilerplate(s *goquery.Selection) bool {
+	if goquery.NodeName(s) == "body" || goquery.NodeName(s) == "article" {
+		return false
+	}
+	class, _ := s.Attr("class")
+	id, _ := s.Attr("id")
+	hint := strings.ToLower(class + " " + id)
+	if strings.TrimSpace(hint) == "" || strings.Contains(hint, "content") {
+		return false
+	}
+	for _, word := range boilerplateHints {
+		if strings.Contains(hint, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDensity is the share of an element's text that sits inside links.
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(strings.TrimSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		linked += len(strings.TrimSpace(a.Text()))
+	})
+	return float64(linked) / float64(total)
+}
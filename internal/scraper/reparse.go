@@ -0,0 +1,78 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file re-runs article extraction over pages kept in the raw archive, so
+// fixed selectors can be applied to the whole site without re-crawling it.
+package scraper
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoArchive is returned by ReparseArticle when ArchiveDir is not configured.
+var ErrNoArchive = errors.New("no archive_dir configured for this website")
+
+// ArchivedURLs returns the URLs with an archived copy, most recently fetched
+// first. since, when not zero, skips pages last fetched before it, and a
+// positive limit caps the number of URLs returned.
+func (as *ArticleScraper) ArchivedURLs(since time.Time, limit int) ([]string, error) {
+	rows, err := as.db.Query(`
+        SELECT url FROM go_raw_pages
+        WHERE website_id = $1
+        GROUP BY url
+        HAVING $2::timestamptz IS NULL OR MAX(fetched_at) >= $2
+        ORDER BY MAX(fetched_at) DESC
+        LIMIT NULLIF($3, 0)
+    `, as.config.ID, sql.NullTime{Time: since, Valid: !since.IsZero()}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived pages: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+// ReparseArticle extracts an article from its most recently archived copy
+// without any network access. The returned article carries the validators
+// of the archived response, so saving it keeps conditional requests working.
+func (as *ArticleScraper) ReparseArticle(url string) (*Article, error) {
+	if as.archive == nil {
+		return nil, ErrNoArchive
+	}
+
+	var hash string
+	var etag, lastModified sql.NullString
+	var fetchedAt time.Time
+	err := as.db.QueryRow(`
+        SELECT sha256, etag, last_modified, fetched_at FROM go_raw_pages
+        WHERE website_id = $1 AND url = $2
+        ORDER BY fetched_at DESC
+        LIMIT 1
+    `, as.config.ID, url).Scan(&hash, &etag, &lastModified, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no archived copy of %s", url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up archived page: %w", err)
+	}
+
+	body, err := as.archive.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	article, err := as.extractArticle(url, body, fetchedAt)
+	if err != nil {
+		return nil, err
+	}
+	article.ETag, article.LastModified = etag.String, lastModified.String
+	return article, nil
+}
@@ -0,0 +1,294 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file handles ingestion of article URLs from a website's numbered post
+// sitemaps into go_sitemaps, which the article scraper then works through.
+package scraper
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+	"github.com/lib/pq"
+)
+
+type SitemapScraper struct {
+	db      *sql.DB
+	config  config.WebsiteConfig
+	fetcher *fetch.Fetcher
+}
+
+func NewSitemapScraper(db *sql.DB, config config.WebsiteConfig) *SitemapScraper {
+	return &SitemapScraper{
+		db:      db,
+		config:  config,
+		fetcher: fetch.New(config),
+	}
+}
+
+// SitemapURLs expands the website's SitemapFormat over StartIndex..EndIndex.
+// When FirstSitemapURL is set it replaces the URL for StartIndex, which
+// covers Yoast-style sites whose first sitemap carries no number.
+func (ss *SitemapScraper) SitemapURLs() []string {
+	if ss.config.SitemapFormat == "" {
+		return nil
+	}
+	var urls []string
+	for i := ss.config.StartIndex; i <= ss.config.EndIndex; i++ {
+		if i == ss.config.StartIndex && ss.config.FirstSitemapURL != "" {
+			urls = append(urls, ss.config.FirstSitemapURL)
+			continue
+		}
+		urls = append(urls, fmt.Sprintf(ss.config.SitemapFormat, i))
+	}
+	return urls
+}
+
+// ScrapeSitemaps processes the given sitemap URLs with up to MaxWorkers
+// running concurrently. Failures of individual sitemaps are logged and do
+// not stop the remaining ones. Sitemaps are requested conditionally on the
+// validators stored in go_sitemap_files and skipped when unchanged, unless
+// full is set.
+func (ss *SitemapScraper) ScrapeSitemaps(sitemaps []string, full bool) error {
+	log.Printf("Starting sitemap scraping for %s (%d sitemaps)", ss.config.Name, len(sitemaps))
+
+	var wg sync.WaitGroup
+	// Semaphore to limit concurrent requests
+	semaphore := make(chan struct{}, max(ss.config.MaxWorkers, 1))
+
+	var completed, failed int32
+	total := len(sitemaps)
+
+	ticker := time.NewTicker(30 * time.Second)
+	go func() {
+		for range ticker.C {
+			log.Printf("Processing status: %d/%d completed", atomic.LoadInt32(&completed), total)
+		}
+	}()
+	defer ticker.Stop()
+
+	for _, sitemapURL := range sitemaps {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() {
+				<-semaphore
+				current := atomic.AddInt32(&completed, 1)
+				log.Printf("Progress: %d/%d sitemaps processed", current, total)
+			}()
+
+			if err := ss.processSitemap(url, full); err != nil {
+				atomic.AddInt32(&failed, 1)
+				log.Printf("Error processing sitemap %s: %v", url, err)
+			}
+		}(sitemapURL)
+	}
+
+	wg.Wait()
+	log.Printf("All sitemaps processed for %s (%d failed)", ss.config.Name, failed)
+	return nil
+}
+
+// processSitemap fetches one sitemap and upserts its URLs in batches of
+// BatchSize inside a single transaction. A sitemap the server reports as
+// not modified is skipped without touching go_sitemaps.
+func (ss *SitemapScraper) processSitemap(sitemapURL string, full bool) error {
+	var validators fetch.Validators
+	if !full {
+		var err error
+		if validators, err = ss.sitemapValidators(sitemapURL); err != nil {
+			return err
+		}
+	}
+
+	resp, err := ss.fetcher.GetIfModified(sitemapURL, validators)
+	if errors.Is(err, fetch.ErrNotModified) {
+		log.Printf("Sitemap not modified: %s", sitemapURL)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch sitemap: %w", err)
+	}
+	defer resp.Body.Close()
+
+	sitemap, err := newSitemapReader(resp.Body, ss.config.MaxSitemapBytes, ss.config.MaxSitemapURLs)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Entries are streamed from the decoder straight into fixed-size
+	// batches, so at most BatchSize URLs are held in memory at once.
+	batchSize := max(ss.config.BatchSize, 1)
+	batch := make([]SitemapURL, 0, batchSize)
+	total := 0
+	flush := func() {
+		if err := ss.insertURLBatch(tx, batch, resp.StatusCode); err != nil {
+			log.Printf("Error inserting batch: %v", err)
+		}
+		total += len(batch)
+		batch = batch[:0]
+	}
+
+	for url, err := range sitemap.URLs() {
+		if err != nil {
+			return err
+		}
+		batch = append(batch, url)
+		if len(batch) >= batchSize {
+			flush()
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+
+	if err := ss.saveSitemapValidators(tx, sitemapURL, fetch.ValidatorsOf(resp), total); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully processed sitemap: %s (%d URLs)", sitemapURL, total)
+	return nil
+}
+
+// sitemapValidators returns the validators stored for a sitemap document,
+// or empty ones if it has not been fetched before.
+func (ss *SitemapScraper) sitemapValidators(sitemapURL string) (fetch.Validators, error) {
+	var etag, lastModified sql.NullString
+	err := ss.db.QueryRow(`
+        SELECT etag, last_modified FROM go_sitemap_files
+        WHERE website_id = $1 AND url = $2
+    `, ss.config.ID, sitemapURL).Scan(&etag, &lastModified)
+	if err != nil && err != sql.ErrNoRows {
+		return fetch.Validators{}, fmt.Errorf("failed to load sitemap validators: %w", err)
+	}
+	return fetch.Validators{ETag: etag.String, LastModified: lastModified.String}, nil
+}
+
+// saveSitemapValidators records the validators of a processed sitemap so the
+// next run can request it conditionally.
+func (ss *SitemapScraper) saveSitemapValidators(tx *sql.Tx, sitemapURL string, v fetch.Validators, urlCount int) error {
+	_, err := tx.Exec(`
+        INSERT INTO go_sitemap_files (website_id, url, etag, last_modified, url_count, last_fetched_at)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NOW())
+        ON CONFLICT (website_id, url) DO UPDATE SET
+            etag = EXCLUDED.etag,
+            last_modified = EXCLUDED.last_modified,
+            url_count = EXCLUDED.url_count,
+            last_fetched_at = NOW()
+    `, ss.config.ID, sitemapURL, v.ETag, v.LastModified, urlCount)
+	if err != nil {
+		return fmt.Errorf("failed to save sitemap validators: %w", err)
+	}
+	return nil
+}
+
+// insertURLBatch upserts a batch of sitemap entries together with their
+// Google News and image extensions, so fresh articles can be prioritised
+// before their HTML is fetched. URLs are stored and later fetched exactly as
+// listed; their canonical form only keys the article they are saved under.
+// Rows already present keep their is_valid and status_code, which article
+// fetches maintain, and the canonical URL a rel=canonical link gave them.
+func (ss *SitemapScraper) insertURLBatch(tx *sql.Tx, batch []SitemapURL, statusCode int) error {
+	stmt, err := tx.Prepare(`
+        INSERT INTO go_sitemaps (
+            website_id, 
+            article_url, 
+            last_mod, 
+            created_at, 
+            is_valid, 
+            status_code,
+            last_checked,
+            news_publication_date,
+            news_title,
+            news_keywords,
+            image_urls,
+            parse_warnings,
+            canonical_url
+        )
+        VALUES ($1, $2, $3, NOW(), true, $4, NOW(), $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (website_id, article_url) 
+        DO UPDATE SET 
+            last_mod = COALESCE($3, go_sitemaps.last_mod),
+            last_checked = NOW(), 
+            news_publication_date = COALESCE($5, go_sitemaps.news_publication_date),
+            news_title = COALESCE($6, go_sitemaps.news_title),
+            news_keywords = COALESCE($7, go_sitemaps.news_keywords),
+            image_urls = COALESCE($8, go_sitemaps.image_urls),
+            parse_warnings = $9,
+            canonical_url = COALESCE(go_sitemaps.canonical_url, $10)
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, url := range batch {
+		var warnings []string
+		lastMod, warning := parseDate("lastmod", url.LastMod, now)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+
+		var (
+			publicationDate *time.Time
+			newsTitle       *string
+			newsKeywords    interface{}
+			imageURLs       interface{}
+			parseWarnings   interface{}
+		)
+		if url.News != nil {
+			publicationDate, warning = parseDate("publication_date", url.News.PublicationDate, now)
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+			if title := strings.TrimSpace(url.News.Title); title != "" {
+				newsTitle = &title
+			}
+			if keywords := url.News.keywords(); len(keywords) > 0 {
+				newsKeywords = pq.Array(keywords)
+			}
+		}
+		if len(url.Images) > 0 {
+			var images []string
+			for _, image := range url.Images {
+				if loc := strings.TrimSpace(image.Loc); loc != "" {
+					images = append(images, loc)
+				}
+			}
+			if len(images) > 0 {
+				imageURLs = pq.Array(images)
+			}
+		}
+
+		if len(warnings) > 0 {
+			log.Printf("Warning: %s in sitemap entry %s", strings.Join(warnings, "; "), url.Loc)
+			parseWarnings = pq.Array(warnings)
+		}
+
+		_, err = stmt.Exec(ss.config.ID, strings.TrimSpace(url.Loc), lastMod, statusCode,
+			publicationDate, newsTitle, newsKeywords, imageURLs, parseWarnings,
+			canonicalURL(url.Loc, ss.config))
+		if err != nil {
+			return fmt.Errorf("failed to execute batch insert: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file contains the streaming sitemap parser shared by the sitemap, category
+// and discovery code. It transparently decompresses gzip sitemaps, understands
+// the Google News and image sitemap extensions and enforces size limits.
+package scraper
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/dates"
+)
+
+// SitemapURL is a single <url> entry of a sitemap.
+type SitemapURL struct {
+	Loc     string           `xml:"loc"`
+	LastMod string           `xml:"lastmod,omitempty"`
+	News    *NewsExtension   `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+	Images  []ImageExtension `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+}
+
+// NewsExtension holds the Google News extension of a <url> entry.
+type NewsExtension struct {
+	PublicationDate string `xml:"publication_date"`
+	Title           string `xml:"title"`
+	Keywords        string `xml:"keywords"`
+}
+
+// ImageExtension holds one image extension entry of a <url> entry.
+type ImageExtension struct {
+	Loc string `xml:"loc"`
+}
+
+// SitemapEntry is a single <sitemap> entry of a sitemap index.
+type SitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Default limits of the sitemap protocol, used when a website does not set
+// its own: 50,000 entries and 50MB of uncompressed XML per document.
+const (
+	defaultMaxSitemapBytes = 50 << 20
+	defaultMaxSitemapURLs  = 50000
+)
+
+// errSitemapTooLarge is returned when a document exceeds its size or entry limit.
+var errSitemapTooLarge = errors.New("sitemap exceeds limit")
+
+// sitemapReader streams the entries of a sitemap or sitemap index without
+// materialising the whole document, so memory use stays bounded by a single
+// entry however large the sitemap is.
+type sitemapReader struct {
+	dec        *xml.Decoder
+	root       string
+	maxEntries int
+	entries    int
+}
+
+// newSitemapReader prepares r for streaming and reads up to the root
+// element. Gzip-compressed bodies (.xml.gz files, which servers send without
+// Content-Encoding) are detected by their magic bytes and decompressed on the
+// fly. The uncompressed document may not exceed maxBytes and may hold at most
+// maxEntries <url> or <sitemap> elements; non-positive values select the
+// protocol defaults.
+func newSitemapReader(r io.Reader, maxBytes int64, maxEntries int) (*sitemapReader, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxSitemapBytes
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxSitemapURLs
+	}
+
+	body, err := maybeGunzip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &sitemapReader{
+		dec:        xml.NewDecoder(&sizeLimitReader{r: body, max: maxBytes}),
+		maxEntries: maxEntries,
+	}
+	for {
+		tok, err := sr.dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			sr.root = start.Name.Local
+			return sr, nil
+		}
+	}
+}
+
+// isIndex reports whether the document is a sitemap index.
+func (sr *sitemapReader) isIndex() bool {
+	return sr.root == "sitemapindex"
+}
+
+// URLs yields the <url> entries of a sitemap.
+func (sr *sitemapReader) URLs() iter.Seq2[SitemapURL, error] {
+	return decodeEntries[SitemapURL](sr, "url")
+}
+
+// Sitemaps yields the <sitemap> entries of a sitemap index.
+func (sr *sitemapReader) Sitemaps() iter.Seq2[SitemapEntry, error] {
+	return decodeEntries[SitemapEntry](sr, "sitemap")
+}
+
+// decodeEntries yields each top-level element called name, decoded into T.
+// Iteration stops at the first error, which is yielded with a zero entry.
+func decodeEntries[T any](sr *sitemapReader, name string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for {
+			start, ok, err := sr.next(name)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !ok {
+				return
+			}
+
+			var entry T
+			if err := sr.dec.DecodeElement(&entry, &start); err != nil {
+				yield(zero, fmt.Errorf("failed to parse <%s>: %w", name, err))
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+// next advances to the next child of the root called name, skipping any
+// other elements. It returns false at the end of the document.
+func (sr *sitemapReader) next(name string) (xml.StartElement, bool, error) {
+	for {
+		tok, err := sr.dec.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, false, nil
+		}
+		if err != nil {
+			return xml.StartElement{}, false, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != name {
+			if err := sr.dec.Skip(); err != nil {
+				return xml.StartElement{}, false, fmt.Errorf("failed to parse XML: %w", err)
+			}
+			continue
+		}
+
+		sr.entries++
+		if sr.entries > sr.maxEntries {
+			return xml.StartElement{}, false, fmt.Errorf("%w: more than %d entries", errSitemapTooLarge, sr.maxEntries)
+		}
+		return start, true, nil
+	}
+}
+
+// sizeLimitReader fails once more than max bytes have been read, guarding
+// against oversized documents and gzip bombs.
+type sizeLimitReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	// Never read more than one byte past the limit, so the decoder cannot
+	// see data beyond it.
+	if remaining := l.max - l.read + 1; int64(len(p)) > remaining {
+		p = p[:max(remaining, 0)]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.max {
+		return n, fmt.Errorf("%w: more than %d bytes", errSitemapTooLarge, l.max)
+	}
+	return n, err
+}
+
+// maybeGunzip wraps r in a gzip reader when it starts with the gzip header.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read sitemap: %w", err)
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		return gz, nil
+	}
+	return br, nil
+}
+
+// keywords splits the comma separated news:keywords value.
+func (n *NewsExtension) keywords() []string {
+	var keywords []string
+	for _, keyword := range strings.Split(n.Keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// parseDate parses a date field with dates.Parse. Empty values yield no
+// date; unrecognised ones yield no date and a warning naming the field, so
+// callers store NULL rather than the zero time.
+func parseDate(field, value string, now time.Time) (*time.Time, string) {
+	if strings.TrimSpace(value) == "" {
+		return nil, ""
+	}
+	t, err := dates.Parse(value, now)
+	if err != nil {
+		return nil, fmt.Sprintf("%s: %v", field, err)
+	}
+	return &t, ""
+}
@@ -0,0 +1,304 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMaybeGunzip(t *testing.T) {
+	const doc = `<?xml version="1.0"?><urlset><url><loc>https://blueprint.ng/a/</loc></url></urlset>`
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"plain", []byte(doc), []byte(doc), false},
+		{"gzip", gzipped(t, doc), []byte(doc), false},
+		{"gzip without xml", gzipped(t, "not xml"), []byte("not xml"), false},
+		{"empty", nil, nil, false},
+		{"single byte", []byte{0x1f}, []byte{0x1f}, false},
+		{"first magic byte only", []byte{0x1f, '<'}, []byte{0x1f, '<'}, false},
+		{"magic bytes only", []byte{0x1f, 0x8b}, nil, true},
+		{"corrupt header", []byte{0x1f, 0x8b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := maybeGunzip(bytes.NewReader(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("maybeGunzip succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("read %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+const extensionsSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
+	xmlns:news="http://www.google.com/schemas/sitemap-news/0.9"
+	xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">
+<url>
+	<loc>https://blueprint.ng/senate-passes-budget/</loc>
+	<lastmod>2024-01-05T10:00:00+01:00</lastmod>
+	<news:news>
+		<news:publication><news:name>Blueprint</news:name><news:language>en</news:language></news:publication>
+		<news:publication_date>2024-01-05T09:30:00+01:00</news:publication_date>
+		<news:title>Senate passes budget</news:title>
+		<news:keywords>Senate, Budget, , Akpabio</news:keywords>
+	</news:news>
+	<image:image><image:loc>https://blueprint.ng/wp-content/uploads/senate.jpg</image:loc></image:image>
+	<image:image><image:loc>https://blueprint.ng/wp-content/uploads/akpabio.jpg</image:loc></image:image>
+</url>
+<url>
+	<loc>https://blueprint.ng/plain-story/</loc>
+</url>
+</urlset>`
+
+func TestSitemapExtensions(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"plain", []byte(extensionsSitemap)},
+		{"gzip", gzipped(t, extensionsSitemap)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := newSitemapReader(bytes.NewReader(tt.body), 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sr.isIndex() {
+				t.Fatal("urlset read as a sitemap index")
+			}
+			var urls []SitemapURL
+			for url, err := range sr.URLs() {
+				if err != nil {
+					t.Fatal(err)
+				}
+				urls = append(urls, url)
+			}
+			if len(urls) != 2 {
+				t.Fatalf("read %d entries, want 2", len(urls))
+			}
+
+			story := urls[0]
+			if story.Loc != "https://blueprint.ng/senate-passes-budget/" || story.LastMod != "2024-01-05T10:00:00+01:00" {
+				t.Errorf("entry = %q, %q", story.Loc, story.LastMod)
+			}
+			if story.News == nil {
+				t.Fatal("news extension missing")
+			}
+			if story.News.PublicationDate != "2024-01-05T09:30:00+01:00" || story.News.Title != "Senate passes budget" {
+				t.Errorf("news = %+v", *story.News)
+			}
+			if got, want := story.News.keywords(), []string{"Senate", "Budget", "Akpabio"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("keywords = %q, want %q", got, want)
+			}
+			want := []ImageExtension{
+				{Loc: "https://blueprint.ng/wp-content/uploads/senate.jpg"},
+				{Loc: "https://blueprint.ng/wp-content/uploads/akpabio.jpg"},
+			}
+			if !reflect.DeepEqual(story.Images, want) {
+				t.Errorf("images = %+v, want %+v", story.Images, want)
+			}
+
+			if plain := urls[1]; plain.News != nil || len(plain.Images) != 0 {
+				t.Errorf("plain entry has extensions: %+v", plain)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    *time.Time
+		warning string
+	}{
+		{"empty", "", nil, ""},
+		{"blank", "  ", nil, ""},
+		{"zone", "2024-01-05T10:00:00+01:00", ptrTime(time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)), ""},
+		{"no zone", "2024-01-05", ptrTime(time.Date(2024, 1, 4, 23, 0, 0, 0, time.UTC)), ""},
+		{"zero date", "0000-00-00 00:00:00", nil, "lastmod: unrecognised date"},
+		{"garbage", "soon", nil, `lastmod: unrecognised date "soon"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warning := parseDate("lastmod", tt.value, now)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("parseDate(%q) = %v, want NULL", tt.value, *got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Errorf("parseDate(%q) = %v, want %v", tt.value, got, *tt.want)
+			}
+			if (tt.warning == "") != (warning == "") || !strings.HasPrefix(warning, tt.warning) {
+				t.Errorf("parseDate(%q) warning = %q, want %q", tt.value, warning, tt.warning)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestSizeLimitReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		max     int64
+		tooLong bool
+	}{
+		{"under", 99, 100, false},
+		{"exact", 100, 100, false},
+		{"one over", 101, 100, true},
+		{"far over", 1 << 20, 100, true},
+		{"empty", 0, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &sizeLimitReader{r: bytes.NewReader(bytes.Repeat([]byte("x"), tt.size)), max: tt.max}
+			got, err := io.ReadAll(r)
+			if tt.tooLong {
+				if !errors.Is(err, errSitemapTooLarge) {
+					t.Fatalf("ReadAll error = %v, want errSitemapTooLarge", err)
+				}
+				// Never more than one byte past the limit is read.
+				if int64(len(got)) > tt.max+1 {
+					t.Errorf("read %d bytes, limit %d", len(got), tt.max)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.size {
+				t.Errorf("read %d bytes, want %d", len(got), tt.size)
+			}
+		})
+	}
+}
+
+// urlset returns a sitemap of n entries, with an unrelated element between
+// each.
+func urlset(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for i := range n {
+		fmt.Fprintf(&b, "<url><loc>https://blueprint.ng/story-%d/</loc></url><!-- ad --><extra>skip</extra>", i)
+	}
+	b.WriteString("</urlset>")
+	return b.String()
+}
+
+func TestSitemapLimits(t *testing.T) {
+	doc := urlset(5)
+	// A gzip bomb: a small compressed file that inflates far past the limit.
+	bomb := gzipped(t, `<?xml version="1.0"?><urlset>`+strings.Repeat(" ", 10<<20)+`</urlset>`)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		maxBytes   int64
+		maxEntries int
+		entries    int
+		tooLarge   bool
+	}{
+		{"defaults", []byte(doc), 0, 0, 5, false},
+		{"entries at limit", []byte(doc), 0, 5, 5, false},
+		{"entries over limit", []byte(doc), 0, 4, 4, true},
+		{"bytes at limit", []byte(doc), int64(len(doc)), 0, 5, false},
+		{"bytes over limit", []byte(doc), int64(len(doc)) - 20, 0, 5, true},
+		{"gzip entries over limit", gzipped(t, doc), 0, 2, 2, true},
+		{"gzip bomb", bomb, 1 << 20, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := newSitemapReader(bytes.NewReader(tt.body), tt.maxBytes, tt.maxEntries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries := 0
+			for _, e := range sr.URLs() {
+				if e != nil {
+					err = e
+					break
+				}
+				entries++
+			}
+			if entries != tt.entries {
+				t.Errorf("read %d entries, want %d", entries, tt.entries)
+			}
+			if errors.Is(err, errSitemapTooLarge) != tt.tooLarge {
+				t.Errorf("error = %v, want too large %v", err, tt.tooLarge)
+			}
+		})
+	}
+}
+
+func TestSitemapIndexLimit(t *testing.T) {
+	index := `<?xml version="1.0"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+		<sitemap><loc>https://blueprint.ng/post-sitemap1.xml</loc><lastmod>2024-01-05</lastmod></sitemap>
+		<sitemap><loc>https://blueprint.ng/post-sitemap2.xml</loc></sitemap>
+		<sitemap><loc>https://blueprint.ng/post-sitemap3.xml</loc></sitemap>
+	</sitemapindex>`
+
+	sr, err := newSitemapReader(strings.NewReader(index), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sr.isIndex() {
+		t.Fatal("sitemap index not recognised")
+	}
+	var locs []string
+	for entry, err := range sr.Sitemaps() {
+		if err != nil {
+			if !errors.Is(err, errSitemapTooLarge) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			break
+		}
+		locs = append(locs, entry.Loc)
+	}
+	want := []string{"https://blueprint.ng/post-sitemap1.xml", "https://blueprint.ng/post-sitemap2.xml"}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("read %q, want %q", locs, want)
+	}
+}
@@ -0,0 +1,189 @@
+// Package scraper implements the core scraping functionality for Nigerian news websites.
+// This file handles post tags: it imports them from the website's tag sitemaps
+// into go_tags, mirroring CategoryScraper, and links scraped articles to the
+// tags their pages list through go_article_tags.
+package scraper
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/config"
+	"github.com/jerryagenyi/go_ng_news_scraper/internal/fetch"
+)
+
+type TagScraper struct {
+	db      *sql.DB
+	config  config.WebsiteConfig
+	fetcher *fetch.Fetcher
+}
+
+func NewTagScraper(db *sql.DB, config config.WebsiteConfig) *TagScraper {
+	return &TagScraper{
+		db:      db,
+		config:  config,
+		fetcher: fetch.New(config),
+	}
+}
+
+// ScrapeTags imports every tag listed in the website's tag sitemaps. Large
+// sites split their tags over several sitemaps, so all discovered ones are
+// read. Tags already named by an article keep that name.
+func (ts *TagScraper) ScrapeTags() error {
+	log.Printf("Starting tag scraping for %s", ts.config.Name)
+
+	sitemapURLs, err := ts.tagSitemapURLs()
+	if err != nil {
+		return err
+	}
+	// Not every site publishes its tags, so run-all carries on without them.
+	if len(sitemapURLs) == 0 {
+		log.Printf("No tag sitemap configured or discovered for %s", ts.config.Name)
+		return nil
+	}
+
+	count := 0
+	for _, sitemapURL := range sitemapURLs {
+		log.Printf("Fetching tags from: %s", sitemapURL)
+		n, err := ts.scrapeTagSitemap(sitemapURL)
+		if err != nil {
+			return err
+		}
+		count += n
+	}
+
+	log.Printf("Successfully processed %d tags for %s", count, ts.config.Name)
+	return nil
+}
+
+func (ts *TagScraper) scrapeTagSitemap(sitemapURL string) (int, error) {
+	resp, err := ts.fetcher.Get(sitemapURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch tag sitemap: %w", err)
+	}
+	defer resp.Body.Close()
+
+	sitemap, err := newSitemapReader(resp.Body, ts.config.MaxSitemapBytes, ts.config.MaxSitemapURLs)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := ts.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`
+    INSERT INTO go_tags (website_id, name, slug, url, created_at)
+    VALUES ($1, $2, $3, $4, NOW())
+    ON CONFLICT (website_id, slug)
+    DO UPDATE SET url = $4
+`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	count := 0
+	for url, err := range sitemap.URLs() {
+		if err != nil {
+			return 0, fmt.Errorf("failed to read tag sitemap: %w", err)
+		}
+		slug, ok := tagSlug(url.Loc)
+		if !ok {
+			log.Printf("Skipping non-tag URL in tag sitemap: %s", url.Loc)
+			continue
+		}
+		count++
+		// A failed insert aborts the transaction, so stop rather than carry
+		// on with statements that would all fail.
+		if _, err := stmt.Exec(ts.config.ID, slugName(slug), slug, url.Loc); err != nil {
+			return 0, fmt.Errorf("failed to insert tag %s: %w", url.Loc, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return count, nil
+}
+
+// tagSitemapURLs returns the configured tag sitemap, or every tag sitemap
+// found by sitemap discovery when none is configured, which may be none.
+func (ts *TagScraper) tagSitemapURLs() ([]string, error) {
+	if ts.config.TagSitemapURL != "" {
+		return []string{ts.config.TagSitemapURL}, nil
+	}
+
+	discovered, err := NewSitemapScraper(ts.db, ts.config).Discover()
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, sitemap := range discovered {
+		if sitemap.Kind == SitemapTag {
+			urls = append(urls, sitemap.URL)
+		}
+	}
+	return urls, nil
+}
+
+// tagSlug extracts the slug of a tag archive URL.
+// Example URL: https://blueprint.ng/tag/tinubu/
+func tagSlug(url string) (string, bool) {
+	_, slug, ok := strings.Cut(url, "/tag/")
+	if !ok {
+		return "", false
+	}
+	slug, _, _ = strings.Cut(slug, "?")
+	slug = strings.Trim(slug, "/")
+	return slug, slug != ""
+}
+
+// slugName turns a slug into a readable name for tags and categories only
+// known from a sitemap.
+func slugName(slug string) string {
+	words := strings.Fields(strings.ReplaceAll(slug, "-", " "))
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// recordTags replaces the article's go_article_tags links. Tags missing
+// from go_tags, because the tag sitemap has not been imported or lags behind
+// the site, are created from the article's links.
+func recordTags(tx *sql.Tx, websiteID, articleID int, article *Article) error {
+	if _, err := tx.Exec(`
+		DELETE FROM go_article_tags WHERE article_id = $1
+	`, articleID); err != nil {
+		return fmt.Errorf("failed to clear existing tags: %w", err)
+	}
+
+	for i, slug := range article.TagSlugs {
+		var tagID int
+		err := tx.QueryRow(`
+			INSERT INTO go_tags (website_id, name, slug, created_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (website_id, slug) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, websiteID, article.Tags[i], slug).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("failed to save tag %s: %w", slug, err)
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO go_article_tags (article_id, tag_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, articleID, tagID); err != nil {
+			return fmt.Errorf("failed to link tag %s: %w", slug, err)
+		}
+	}
+	return nil
+}
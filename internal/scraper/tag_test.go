@@ -0,0 +1,28 @@
+package scraper
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlugName(t *testing.T) {
+	tests := []struct {
+		slug string
+		want string
+	}{
+		{"tinubu", "Tinubu"},
+		{"super-eagles", "Super Eagles"},
+		{"ébola-outbreak", "Ébola Outbreak"},
+		{"ọ̀yọ́-state", "Ọ̀yọ́ State"},
+		{"2023-elections", "2023 Elections"},
+		{"--apc--", "Apc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			got := slugName(tt.slug)
+			if got != tt.want || !utf8.ValidString(got) {
+				t.Errorf("slugName(%q) = %q, want %q", tt.slug, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package thumbnail keeps scaled-down copies of article images in a
+// content-addressed directory. Each thumbnail is a JPEG named after the
+// sha256 of the original image, fanned out into two levels of
+// subdirectories like the page archive, so an image shared by several
+// articles is only stored once.
+package thumbnail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for the formats news sites serve
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Limits on downloaded images. A small file can declare huge dimensions,
+// so the pixel count is checked before the image is decoded.
+const (
+	maxImageBytes  = 20 << 20
+	maxImagePixels = 50_000_000
+)
+
+// Store is a directory of JPEG thumbnails.
+type Store struct {
+	dir   string
+	width int
+}
+
+// Open returns the store rooted at dir, creating the directory if needed.
+// Thumbnails are scaled to width pixels; smaller images keep their size.
+func Open(dir string, width int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+	return &Store{dir: dir, width: width}, nil
+}
+
+// path returns where the thumbnail of the image with the given hash lives,
+// e.g. ab/cd/abcd….jpg.
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash[2:4], hash+".jpg")
+}
+
+// Save reads an image from r, stores its thumbnail and returns the sha256 of
+// the original image. Thumbnails already in the store are not written again.
+func (s *Store) Save(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return "", fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return "", fmt.Errorf("image dimensions %dx%d exceed %d pixels", cfg.Width, cfg.Height, maxImagePixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	thumb := s.scale(src)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create thumbnail file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := jpeg.Encode(tmp, thumb, &jpeg.Options{Quality: 80}); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+	return hash, nil
+}
+
+// scale resizes src to the store's width, keeping its aspect ratio.
+func (s *Store) scale(src image.Image) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= s.width {
+		return src
+	}
+	height := max(bounds.Dy()*s.width/bounds.Dx(), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, s.width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
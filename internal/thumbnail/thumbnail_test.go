@@ -0,0 +1,90 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+)
+
+// encodePNG returns a w×h PNG.
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withDimensions rewrites the IHDR chunk of a PNG to declare w×h, leaving
+// the pixel data as it was.
+func withDimensions(data []byte, w, h uint32) []byte {
+	out := bytes.Clone(data)
+	ihdr := out[8:] // After the signature: length, "IHDR", data, CRC
+	binary.BigEndian.PutUint32(ihdr[8:], w)
+	binary.BigEndian.PutUint32(ihdr[12:], h)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+	return out
+}
+
+func TestSave(t *testing.T) {
+	small := encodePNG(t, 40, 30)
+	wide := encodePNG(t, 400, 200)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		width  int
+		height int
+		err    string
+	}{
+		{"smaller than the width", small, 40, 30, ""},
+		{"scaled to the width", wide, 100, 50, ""},
+		{"huge declared dimensions", withDimensions(small, 100_000, 100_000), 0, 0, "exceed"},
+		{"too many pixels in one row", withDimensions(small, 60_000_000, 1), 0, 0, "exceed"},
+		{"not an image", []byte("<html></html>"), 0, 0, "failed to decode"},
+		{"too many bytes", bytes.Repeat([]byte{0}, maxImageBytes+1), 0, 0, "exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(t.TempDir(), 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash, err := store.Save(bytes.NewReader(tt.data))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Save error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := os.Open(store.path(hash))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			thumb, err := jpeg.DecodeConfig(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if thumb.Width != tt.width || thumb.Height != tt.height {
+				t.Errorf("thumbnail is %dx%d, want %dx%d", thumb.Width, thumb.Height, tt.width, tt.height)
+			}
+		})
+	}
+}